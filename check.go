@@ -0,0 +1,103 @@
+/*
+check.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jftuga/nics/nicinfo"
+	"github.com/olekukonko/tablewriter"
+)
+
+// checkCommand implements: nics check -policy <policy.yaml>
+// the exit code is 0 when every check passed, 1 when one failed and 2 on errors
+func checkCommand(args []string, output string) int {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	argsPolicy := fs.String("policy", "", "YAML file with the expected interfaces, gateway and DNS servers")
+	_ = fs.Parse(args)
+	if len(*argsPolicy) == 0 {
+		fmt.Fprintf(os.Stderr, "usage: nics check -policy <policy.yaml>\n")
+		return 2
+	}
+
+	policy, err := nicinfo.ReadPolicy(*argsPolicy)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	snap, err := nicinfo.Collect(context.Background(), nicinfo.Options{})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	results := policy.Check(snap)
+	if len(results) == 0 {
+		fmt.Fprintf(os.Stderr, "%s: policy has no checks\n", *argsPolicy)
+		return 2
+	}
+
+	failed := 0
+	for _, result := range results {
+		if !result.Passed {
+			failed++
+		}
+	}
+
+	headers := []string{"Result", "Check", "Detail"}
+	var rows [][]string
+	for _, result := range results {
+		status := "PASS"
+		if !result.Passed {
+			status = "FAIL"
+		}
+		rows = append(rows, []string{status, result.Check, result.Detail})
+	}
+	summary := fmt.Sprintf("%d passed, %d failed", len(results)-failed, failed)
+
+	switch output {
+	case "table":
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetAutoWrapText(false)
+		table.SetHeader(headers)
+		table.AppendBulk(rows)
+		table.SetCaption(true, summary)
+		table.Render()
+	case "csv", "tsv", "markdown", "html":
+		err = renderRows(os.Stdout, output, "Policy Check", headers, rows, summary)
+	default:
+		err = renderRecords(os.Stdout, output, "check", results)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	return checkExitCode(results)
+}
+
+// checkExitCode maps the results of a policy to the exit code of nics check: 0 when every
+// check passed, 1 when one failed and 2 when the policy had nothing to check
+func checkExitCode(results []nicinfo.CheckResult) int {
+	if len(results) == 0 {
+		return 2
+	}
+	for _, result := range results {
+		if !result.Passed {
+			return 1
+		}
+	}
+	return 0
+}
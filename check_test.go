@@ -0,0 +1,41 @@
+/*
+check_test.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"github.com/jftuga/nics/nicinfo"
+)
+
+func TestCheckExitCode(t *testing.T) {
+	pass := nicinfo.CheckResult{Check: "eth0 is up", Passed: true}
+	fail := nicinfo.CheckResult{Check: "eth0 mtu 9000", Passed: false}
+	tests := []struct {
+		name    string
+		results []nicinfo.CheckResult
+		want    int
+	}{
+		{"all passed", []nicinfo.CheckResult{pass, pass}, 0},
+		{"one failed", []nicinfo.CheckResult{pass, fail}, 1},
+		{"all failed", []nicinfo.CheckResult{fail, fail}, 1},
+		{"nothing checked", nil, 2},
+	}
+
+	for _, tt := range tests {
+		if got := checkExitCode(tt.results); got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+/*
+delimited.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*/
+
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jftuga/nics/nicinfo"
+)
+
+// csv and tsv output can have one row per interface, or one row per address
+var delimitedRows = []string{"interface", "address"}
+
+// csv and tsv output sections, "all" writes every section separated by an empty line
+var delimitedSections = []string{"all", "interfaces", "dhcp", "gateways", "dns"}
+
+// interfaceRows flattens the interface table; with perAddress every address gets a row of its own
+// under the IP column, otherwise the addresses and gateways of an interface are joined with sep
+func interfaceRows(allInterfaces []nicinfo.Interface, defaultRoutes []nicinfo.Route, brief, perAddress bool, sep string) ([]string, [][]string) {
+	gateways := interfaceGateways(defaultRoutes)
+	headers := interfaceHeaders
+	if brief || perAddress {
+		headers = briefInterfaceHeaders
+	}
+
+	var rows [][]string
+	for _, nic := range allInterfaces {
+		allIPv4 := addressStrings(nic.IPv4)
+		allIPv6 := addressStrings(nic.IPv6)
+		gateway := strings.Join(gateways[nic.Name], sep)
+		mtu := strconv.Itoa(nic.MTU)
+		flags := strings.Join(nic.Flags, "|")
+
+		switch {
+		case perAddress:
+			addresses := allIPv4
+			if !brief {
+				addresses = append(addresses, allIPv6...)
+			}
+			if len(addresses) == 0 {
+				addresses = []string{""}
+			}
+			for _, addr := range addresses {
+				rows = append(rows, []string{nic.Name, addr, gateway, nic.MAC, mtu, flags})
+			}
+		case brief:
+			rows = append(rows, []string{nic.Name, strings.Join(allIPv4, sep), gateway, nic.MAC, mtu, flags})
+		default:
+			rows = append(rows, []string{nic.Name, strings.Join(allIPv4, sep), strings.Join(allIPv6, sep), gateway, nic.MAC, mtu, flags})
+		}
+	}
+	return headers, rows
+}
+
+// renderDelimited writes the interface, DHCP, gateway and DNS tables as csv, or as tsv when format is tsv;
+// section selects a single table, so that each one can be saved to a file of its own
+func renderDelimited(w io.Writer, format string, snap *nicinfo.Snapshot, brief, perAddress bool, section string) error {
+	type table struct {
+		name    string
+		headers []string
+		rows    [][]string
+	}
+	var tables []table
+
+	headers, rows := interfaceRows(snap.Interfaces, snap.DefaultRoutes(), brief, perAddress, " ")
+	tables = append(tables, table{"interfaces", headers, rows})
+	rows = nil
+	for _, lease := range snap.DHCP {
+		rows = append(rows, dhcpRow(lease))
+	}
+	tables = append(tables, table{"dhcp", dhcpHeaders, rows})
+	rows = nil
+	for _, r := range snap.DefaultRoutes() {
+		rows = append(rows, gatewayRow(r))
+	}
+	tables = append(tables, table{"gateways", gatewayHeaders, rows})
+	tables = append(tables, table{"dns", dnsHeaders, dnsRows(snap.Resolver)})
+
+	if !slices.Contains(delimitedSections, section) {
+		return fmt.Errorf("invalid section: %s", section)
+	}
+	out := csv.NewWriter(w)
+	if format == "tsv" {
+		out.Comma = '\t'
+	}
+	first := true
+	for _, t := range tables {
+		if section != "all" && section != t.name {
+			continue
+		}
+		if section == "all" && len(t.rows) == 0 {
+			continue
+		}
+		if !first {
+			// an empty line separates the sections
+			if err := out.Write(nil); err != nil {
+				return err
+			}
+		}
+		first = false
+		if err := out.Write(t.headers); err != nil {
+			return err
+		}
+		if err := out.WriteAll(t.rows); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
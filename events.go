@@ -0,0 +1,65 @@
+/*
+events.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/jftuga/nics/nicinfo"
+)
+
+// eventsCommand implements: nics events [-o text|ndjson] [-i interface]
+// it prints one line per link, address or route change until interrupted
+func eventsCommand(args []string, output, singleInterface string) int {
+	defaultFormat := "text"
+	if output == "ndjson" {
+		defaultFormat = output
+	}
+	fs := flag.NewFlagSet("events", flag.ExitOnError)
+	argsOutput := fs.String("o", defaultFormat, "output format: text or ndjson")
+	argsSingleInterface := fs.String("i", singleInterface, "only show events of this interface")
+	_ = fs.Parse(args)
+
+	if *argsOutput != "text" && *argsOutput != "ndjson" {
+		fmt.Fprintf(os.Stderr, "invalid output format: %s\n", *argsOutput)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	enc := json.NewEncoder(os.Stdout)
+	err := nicinfo.WatchEvents(ctx, func(event nicinfo.Event) {
+		if len(*argsSingleInterface) > 0 && !strings.EqualFold(event.Interface, *argsSingleInterface) {
+			return
+		}
+		if *argsOutput == "ndjson" {
+			_ = enc.Encode(ndjsonRecord{nicinfo.SchemaVersion, "event", event})
+			return
+		}
+		fmt.Printf("%s %s\n", event.Time.Format("2006-01-02 15:04:05"), event)
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
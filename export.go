@@ -0,0 +1,88 @@
+/*
+export.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/jftuga/nics/nicinfo"
+)
+
+// exportShells are the shells that -export can write variable assignments for
+var exportShells = []string{"sh", "fish", "powershell"}
+
+// exportName turns an interface name into part of a variable name, such as: br-1a2b becomes BR_1A2B
+func exportName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return '_'
+		}
+		return unicode.ToUpper(r)
+	}, name)
+}
+
+// exportAssignment returns a statement that sets and exports an environment variable in shell
+func exportAssignment(shell, name, value string) string {
+	switch shell {
+	case "fish":
+		return fmt.Sprintf("set -gx %s '%s'", name, strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(value))
+	case "powershell":
+		return fmt.Sprintf("$env:%s = '%s'", name, strings.ReplaceAll(value, "'", "''"))
+	}
+	return fmt.Sprintf("export %s='%s'", name, strings.ReplaceAll(value, "'", `'\''`))
+}
+
+// renderExport writes variables for the first IPv4 and IPv6 address and the MAC address of each
+// interface, followed by the primary address, the default gateway and the DNS servers
+func renderExport(w io.Writer, shell string, snap *nicinfo.Snapshot) error {
+	type variable struct{ name, value string }
+	var variables []variable
+
+	first := func(allAddresses []nicinfo.Address) string {
+		if len(allAddresses) == 0 {
+			return ""
+		}
+		return allAddresses[0].IP
+	}
+	for _, nic := range snap.Interfaces {
+		prefix := "NICS_" + exportName(nic.Name) + "_"
+		variables = append(variables,
+			variable{prefix + "IPV4", first(nic.IPv4)},
+			variable{prefix + "IPV6", first(nic.IPv6)},
+			variable{prefix + "MAC", nic.MAC})
+	}
+
+	iface, addr, _ := snap.PrimaryAddress()
+	variables = append(variables, variable{"NICS_PRIMARY_IFACE", iface}, variable{"NICS_PRIMARY_IP", addr})
+	gateway := ""
+	if defaultRoutes := snap.DefaultRoutes(); len(defaultRoutes) > 0 {
+		gateway = defaultRoutes[0].Gateway
+	}
+	variables = append(variables, variable{"NICS_DEFAULT_GW", gateway})
+	var servers []string
+	for _, server := range snap.Resolver.Servers {
+		servers = append(servers, server.Address)
+	}
+	variables = append(variables, variable{"NICS_DNS", strings.Join(servers, " ")})
+
+	for _, v := range variables {
+		if _, err := fmt.Fprintln(w, exportAssignment(shell, v.name, v.value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+/*
+get.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jftuga/nics/nicinfo"
+)
+
+// getFields are the values that nics get can print
+var getFields = []string{"ipv4", "ipv6", "gateway", "mac", "mtu", "dns", "primary-ip"}
+
+// getValues returns the values of field; iface defaults to the interface of the preferred default route,
+// and without iface the gateway is the one of the preferred default route
+func getValues(snap *nicinfo.Snapshot, field, iface string, cidr bool) ([]string, error) {
+	switch {
+	case field == "gateway" && len(iface) == 0:
+		defaultRoutes := snap.DefaultRoutes()
+		if len(defaultRoutes) == 0 {
+			return nil, fmt.Errorf("no default route")
+		}
+		return []string{defaultRoutes[0].Gateway}, nil
+	case field == "primary-ip":
+		_, addr, ok := snap.PrimaryAddress()
+		if !ok {
+			return nil, fmt.Errorf("no default route")
+		}
+		return []string{addr}, nil
+	case field == "dns":
+		var servers []string
+		for _, server := range snap.Resolver.Servers {
+			if !slices.Contains(servers, server.Address) {
+				servers = append(servers, server.Address)
+			}
+		}
+		return servers, nil
+	}
+
+	if len(iface) == 0 {
+		primary, _, ok := snap.PrimaryAddress()
+		if !ok {
+			return nil, fmt.Errorf("no default route, name an interface")
+		}
+		iface = primary
+	}
+	idx := slices.IndexFunc(snap.Interfaces, func(nic nicinfo.Interface) bool { return strings.EqualFold(nic.Name, iface) })
+	if idx < 0 {
+		return nil, fmt.Errorf("%w: %s", nicinfo.ErrInterfaceNotFound, iface)
+	}
+	nic := snap.Interfaces[idx]
+
+	addresses := func(allAddresses []nicinfo.Address) []string {
+		if cidr {
+			return addressStrings(allAddresses)
+		}
+		var result []string
+		for _, addr := range allAddresses {
+			result = append(result, addr.IP)
+		}
+		return result
+	}
+	switch field {
+	case "ipv4":
+		return addresses(nic.IPv4), nil
+	case "ipv6":
+		return addresses(nic.IPv6), nil
+	case "gateway":
+		return interfaceGateways(snap.DefaultRoutes())[nic.Name], nil
+	case "mac":
+		if len(nic.MAC) == 0 {
+			return nil, nil
+		}
+		return []string{nic.MAC}, nil
+	case "mtu":
+		return []string{strconv.Itoa(nic.MTU)}, nil
+	}
+	return nil, fmt.Errorf("unknown field: %s", field)
+}
+
+// getCommand implements: nics get [-cidr] <field> [interface]
+// it prints each value on a line of its own; the exit code is 0 when a value was found, 1 when
+// none was and 2 on usage errors
+func getCommand(args []string, singleInterface string) int {
+	fs := flag.NewFlagSet("get", flag.ExitOnError)
+	argsCIDR := fs.Bool("cidr", false, "include the prefix length of addresses")
+	_ = fs.Parse(args)
+	if fs.NArg() < 1 || fs.NArg() > 2 || !slices.Contains(getFields, fs.Arg(0)) {
+		fmt.Fprintf(os.Stderr, "usage: nics get [-cidr] <%s> [interface]\n", strings.Join(getFields, "|"))
+		return 2
+	}
+	iface := singleInterface
+	if fs.NArg() == 2 {
+		iface = fs.Arg(1)
+	}
+
+	snap, err := nicinfo.Collect(context.Background(), nicinfo.Options{})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	values, err := getValues(snap, fs.Arg(0), iface, *argsCIDR)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if len(values) == 0 {
+		fmt.Fprintf(os.Stderr, "no %s found\n", fs.Arg(0))
+		return 1
+	}
+	for _, value := range values {
+		fmt.Println(value)
+	}
+	return 0
+}
@@ -5,6 +5,7 @@ go 1.24.1
 require (
 	github.com/olekukonko/tablewriter v0.0.5
 	golang.org/x/net v0.38.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require github.com/mattn/go-runewidth v0.0.9 // indirect
@@ -0,0 +1,133 @@
+/*
+hooks.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"syscall"
+	"time"
+
+	"github.com/jftuga/nics/nicinfo"
+)
+
+// hook names, these are also the names of the flags that configure them
+const (
+	hookIPChange      = "on-ip-change"
+	hookLinkDown      = "on-link-down"
+	hookGatewayChange = "on-gateway-change"
+)
+
+// hookFor returns the name of the hook that handles change, or an empty string
+func hookFor(change nicinfo.Change) string {
+	switch change.Field {
+	case nicinfo.FieldIPv4, nicinfo.FieldIPv6:
+		return hookIPChange
+	case nicinfo.FieldGateway:
+		return hookGatewayChange
+	case nicinfo.FieldState:
+		if change.Old == nicinfo.StateUp {
+			return hookLinkDown
+		}
+	}
+	return ""
+}
+
+// runHook runs command with the shell; the change is described by the NICS_HOOK, NICS_INTERFACE,
+// NICS_FIELD, NICS_OLD and NICS_NEW environment variables
+func runHook(ctx context.Context, hook, command string, change nicinfo.Change) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "/bin/sh", "-c", command)
+	}
+	cmd.Env = append(os.Environ(),
+		"NICS_HOOK="+hook,
+		"NICS_INTERFACE="+change.Interface,
+		"NICS_FIELD="+change.Field,
+		"NICS_OLD="+change.Old,
+		"NICS_NEW="+change.New,
+	)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// hooksCommand implements: nics hooks [-n interval] [-on-ip-change command] [-on-link-down command]
+// [-on-gateway-change command]; it compares a new snapshot with the previous one every interval,
+// prints each change and runs the command configured for it
+func hooksCommand(args []string, singleInterface string) int {
+	fs := flag.NewFlagSet("hooks", flag.ExitOnError)
+	argsInterval := fs.String("n", "5", "polling interval, in seconds or as a duration such as 500ms")
+	argsSingleInterface := fs.String("i", singleInterface, "only watch this interface")
+	hooks := map[string]*string{
+		hookIPChange:      fs.String(hookIPChange, "", "command to run when an IPv4 or IPv6 address changes"),
+		hookLinkDown:      fs.String(hookLinkDown, "", "command to run when an interface goes down or disappears"),
+		hookGatewayChange: fs.String(hookGatewayChange, "", "command to run when the default gateway of an interface changes"),
+	}
+	_ = fs.Parse(args)
+
+	interval, err := parseInterval(*argsInterval)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// every interface is collected, the brief filter would hide an interface that lost its IPv4 address
+	opts := nicinfo.Options{Interface: *argsSingleInterface}
+	previous, err := nicinfo.Collect(ctx, opts)
+	if err != nil && !errors.Is(err, nicinfo.ErrInterfaceNotFound) {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return 0
+		case <-ticker.C:
+		}
+
+		current, err := nicinfo.Collect(ctx, opts)
+		if err != nil && !errors.Is(err, nicinfo.ErrInterfaceNotFound) {
+			if ctx.Err() == nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			continue
+		}
+		for _, change := range nicinfo.Compare(previous, current) {
+			fmt.Printf("%s %s\n", time.Now().Format("2006-01-02 15:04:05"), change)
+			hook := hookFor(change)
+			if len(hook) == 0 || len(*hooks[hook]) == 0 {
+				continue
+			}
+			if err := runHook(ctx, hook, *hooks[hook], change); err != nil && ctx.Err() == nil {
+				fmt.Fprintf(os.Stderr, "%s hook for %s: %v\n", hook, change.Interface, err)
+			}
+		}
+		previous = current
+	}
+}
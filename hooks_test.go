@@ -0,0 +1,42 @@
+/*
+hooks_test.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"github.com/jftuga/nics/nicinfo"
+)
+
+func TestHookFor(t *testing.T) {
+	tests := []struct {
+		change nicinfo.Change
+		want   string
+	}{
+		{nicinfo.Change{Interface: "eth0", Field: nicinfo.FieldIPv4, Old: "192.0.2.2/24", New: "192.0.2.3/24"}, hookIPChange},
+		{nicinfo.Change{Interface: "tun0", Field: nicinfo.FieldIPv6, New: "2001:db8::6/64"}, hookIPChange},
+		{nicinfo.Change{Interface: "eth0", Field: nicinfo.FieldGateway, Old: "192.0.2.1"}, hookGatewayChange},
+		{nicinfo.Change{Interface: "eth0", Field: nicinfo.FieldState, Old: nicinfo.StateUp, New: nicinfo.StateDown}, hookLinkDown},
+		{nicinfo.Change{Interface: "eth0", Field: nicinfo.FieldState, Old: nicinfo.StateUp, New: nicinfo.StateAbsent}, hookLinkDown},
+		{nicinfo.Change{Interface: "eth0", Field: nicinfo.FieldState, Old: nicinfo.StateAbsent, New: nicinfo.StateUp}, ""},
+		{nicinfo.Change{Interface: "eth0", Field: nicinfo.FieldMTU, Old: "1500", New: "9000"}, ""},
+		{nicinfo.Change{Field: nicinfo.FieldDNS, Old: "192.0.2.53"}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := hookFor(tt.change); got != tt.want {
+			t.Errorf("hookFor(%s) = %q, want %q", tt.change, got, tt.want)
+		}
+	}
+}
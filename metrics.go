@@ -0,0 +1,134 @@
+/*
+metrics.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jftuga/nics/nicinfo"
+)
+
+// metricsContentType is the Prometheus text exposition format
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// labelEscaper escapes label values as the exposition format requires
+var labelEscaper = strings.NewReplacer(`\`, `\\`, "\"", `\"`, "\n", `\n`)
+
+// metricFamily writes the HELP and TYPE lines of a metric, followed by one sample per call of sample
+type metricFamily struct {
+	w    io.Writer
+	name string
+}
+
+func newMetricFamily(w io.Writer, name, metricType, help string) *metricFamily {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+	return &metricFamily{w, name}
+}
+
+// sample writes one value; labels are name, value pairs
+func (m *metricFamily) sample(value float64, labels ...string) {
+	var pairs []string
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, labels[i]+"=\""+labelEscaper.Replace(labels[i+1])+"\"")
+	}
+	name := m.name
+	if len(pairs) > 0 {
+		name += "{" + strings.Join(pairs, ",") + "}"
+	}
+	fmt.Fprintf(m.w, "%s %s\n", name, strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// counterMetric is one of the InterfaceStats counters
+type counterMetric struct {
+	name, help string
+	value      func(*nicinfo.InterfaceStats) uint64
+}
+
+var counterMetrics = []counterMetric{
+	{"nics_interface_receive_bytes_total", "Bytes received.", func(s *nicinfo.InterfaceStats) uint64 { return s.RxBytes }},
+	{"nics_interface_receive_packets_total", "Packets received.", func(s *nicinfo.InterfaceStats) uint64 { return s.RxPackets }},
+	{"nics_interface_receive_errors_total", "Receive errors.", func(s *nicinfo.InterfaceStats) uint64 { return s.RxErrors }},
+	{"nics_interface_receive_dropped_total", "Received packets that were dropped.", func(s *nicinfo.InterfaceStats) uint64 { return s.RxDropped }},
+	{"nics_interface_receive_overruns_total", "Receive FIFO overruns.", func(s *nicinfo.InterfaceStats) uint64 { return s.RxOverruns }},
+	{"nics_interface_receive_multicast_total", "Multicast packets received.", func(s *nicinfo.InterfaceStats) uint64 { return s.Multicast }},
+	{"nics_interface_transmit_bytes_total", "Bytes transmitted.", func(s *nicinfo.InterfaceStats) uint64 { return s.TxBytes }},
+	{"nics_interface_transmit_packets_total", "Packets transmitted.", func(s *nicinfo.InterfaceStats) uint64 { return s.TxPackets }},
+	{"nics_interface_transmit_errors_total", "Transmit errors.", func(s *nicinfo.InterfaceStats) uint64 { return s.TxErrors }},
+	{"nics_interface_transmit_dropped_total", "Transmitted packets that were dropped.", func(s *nicinfo.InterfaceStats) uint64 { return s.TxDropped }},
+	{"nics_interface_transmit_overruns_total", "Transmit FIFO overruns.", func(s *nicinfo.InterfaceStats) uint64 { return s.TxOverruns }},
+}
+
+// writeMetrics writes snap in the Prometheus text exposition format; interface metrics are labeled
+// with the interface name and MAC address
+func writeMetrics(w io.Writer, snap *nicinfo.Snapshot) {
+	labels := func(nic nicinfo.Interface, extra ...string) []string {
+		return append([]string{"interface", nic.Name, "mac", nic.MAC}, extra...)
+	}
+
+	m := newMetricFamily(w, "nics_interface_up", "gauge", "Whether the interface is administratively up.")
+	for _, nic := range snap.Interfaces {
+		m.sample(boolValue(slices.Contains(nic.Flags, "up")), labels(nic)...)
+	}
+	m = newMetricFamily(w, "nics_interface_running", "gauge", "Whether the interface is operationally up.")
+	for _, nic := range snap.Interfaces {
+		m.sample(boolValue(slices.Contains(nic.Flags, "running")), labels(nic)...)
+	}
+	m = newMetricFamily(w, "nics_interface_mtu_bytes", "gauge", "MTU of the interface.")
+	for _, nic := range snap.Interfaces {
+		m.sample(float64(nic.MTU), labels(nic)...)
+	}
+	m = newMetricFamily(w, "nics_interface_addresses", "gauge", "Number of addresses of the interface.")
+	for _, nic := range snap.Interfaces {
+		m.sample(float64(len(nic.IPv4)), labels(nic, "family", "ipv4")...)
+		m.sample(float64(len(nic.IPv6)), labels(nic, "family", "ipv6")...)
+	}
+	m = newMetricFamily(w, "nics_interface_speed_bytes", "gauge", "Link speed in bytes per second, for interfaces that report one.")
+	for _, nic := range snap.Interfaces {
+		if speed, err := nicinfo.ReadLinkSpeed(nic.Name); err == nil {
+			m.sample(float64(speed)*1000*1000/8, labels(nic)...)
+		}
+	}
+	for _, counter := range counterMetrics {
+		m = newMetricFamily(w, counter.name, "counter", counter.help)
+		for _, nic := range snap.Interfaces {
+			if nic.Stats != nil {
+				m.sample(float64(counter.value(nic.Stats)), labels(nic)...)
+			}
+		}
+	}
+
+	m = newMetricFamily(w, "nics_dhcp_lease_seconds_remaining", "gauge", "Seconds until the DHCP lease of the interface expires.")
+	now := time.Now()
+	for _, lease := range snap.DHCP {
+		if remaining, ok := lease.ExpiresIn(now); ok {
+			m.sample(remaining.Seconds(), "interface", lease.Interface)
+		}
+	}
+	m = newMetricFamily(w, "nics_default_gateway_present", "gauge", "Whether a default route with a gateway exists.")
+	m.sample(boolValue(len(snap.DefaultRoutes()) > 0))
+	m = newMetricFamily(w, "nics_dns_servers", "gauge", "Number of configured DNS servers.")
+	m.sample(float64(len(snap.Resolver.Servers)))
+}
@@ -0,0 +1,198 @@
+/*
+nagios.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jftuga/nics/nicinfo"
+)
+
+// monitoring plugin states and their exit codes
+const (
+	pluginOK = iota
+	pluginWarning
+	pluginCritical
+	pluginUnknown
+)
+
+var pluginStates = []string{"OK", "WARNING", "CRITICAL", "UNKNOWN"}
+
+// pluginSeverity ranks the states from best to worst; a known WARNING or CRITICAL is more useful than UNKNOWN
+var pluginSeverity = []int{pluginOK, pluginUnknown, pluginWarning, pluginCritical}
+
+// pluginResult accumulates the problems and performance data of one plugin run;
+// the worst state of all problems wins
+type pluginResult struct {
+	state    int
+	problems []string
+	perfdata []string
+}
+
+func (r *pluginResult) add(state int, problem string) {
+	if slices.Index(pluginSeverity, state) > slices.Index(pluginSeverity, r.state) {
+		r.state = state
+	}
+	r.problems = append(r.problems, problem)
+}
+
+// threshold is an optional upper limit; an empty flag disables it
+type threshold struct {
+	set   bool
+	value float64
+}
+
+func (t *threshold) String() string {
+	if !t.set {
+		return ""
+	}
+	return strconv.FormatFloat(t.value, 'f', -1, 64)
+}
+
+func (t *threshold) Set(value string) error {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return err
+	}
+	t.value, t.set = v, true
+	return nil
+}
+
+// check returns the state of value against the warning and critical thresholds
+func check(value float64, warning, critical threshold) int {
+	switch {
+	case critical.set && value > critical.value:
+		return pluginCritical
+	case warning.set && value > warning.value:
+		return pluginWarning
+	}
+	return pluginOK
+}
+
+// nagiosCommand implements: nics nagios [-i eth0,eth1] [-address prefix] [-gateway] [-warn-errors n]
+// [-crit-errors n] [-warn-drops n] [-crit-drops n] [-n interval]
+// it prints a single monitoring plugin line, such as: OK - eth0 up 10.1.2.15/24 | perfdata,
+// and returns 0, 1, 2 or 3 for OK, WARNING, CRITICAL or UNKNOWN
+func nagiosCommand(args []string, singleInterface string) int {
+	fs := flag.NewFlagSet("nagios", flag.ContinueOnError)
+	argsInterfaces := fs.String("i", singleInterface, "comma separated interfaces to check, the brief interfaces by default")
+	argsInterval := fs.String("n", "1", "sampling interval for the error and drop rates")
+	argsGateway := fs.Bool("gateway", false, "critical when an interface has no default gateway")
+	var addresses []string
+	fs.Func("address", "an address or network each interface must have; may be repeated", func(value string) error {
+		addresses = append(addresses, value)
+		return nil
+	})
+	var warnErrors, critErrors, warnDrops, critDrops threshold
+	fs.Var(&warnErrors, "warn-errors", "warning when rx+tx errors per second exceed this")
+	fs.Var(&critErrors, "crit-errors", "critical when rx+tx errors per second exceed this")
+	fs.Var(&warnDrops, "warn-drops", "warning when rx+tx drops per second exceed this")
+	fs.Var(&critDrops, "crit-drops", "critical when rx+tx drops per second exceed this")
+	if err := fs.Parse(args); err != nil {
+		fmt.Printf("UNKNOWN - %v\n", err)
+		return pluginUnknown
+	}
+	interval, err := parseInterval(*argsInterval)
+	if err != nil {
+		fmt.Printf("UNKNOWN - %v\n", err)
+		return pluginUnknown
+	}
+
+	var names []string
+	if len(*argsInterfaces) > 0 {
+		names = strings.Split(*argsInterfaces, ",")
+	}
+	snap, err := nicinfo.Collect(context.Background(), nicinfo.Options{Brief: len(names) == 0})
+	if err != nil {
+		fmt.Printf("UNKNOWN - %v\n", err)
+		return pluginUnknown
+	}
+	if len(names) == 0 {
+		for _, nic := range snap.Interfaces {
+			names = append(names, nic.Name)
+		}
+	}
+	gateways := interfaceGateways(snap.DefaultRoutes())
+	rates := warnErrors.set || critErrors.set || warnDrops.set || critDrops.set
+	if rates {
+		time.Sleep(interval)
+	}
+
+	result := &pluginResult{}
+	var summary []string
+	for _, name := range names {
+		idx := slices.IndexFunc(snap.Interfaces, func(nic nicinfo.Interface) bool { return nic.Name == name })
+		if idx < 0 {
+			result.add(pluginCritical, name+" not found")
+			continue
+		}
+		nic := snap.Interfaces[idx]
+		if nic.State() != nicinfo.StateUp {
+			result.add(pluginCritical, name+" is down")
+		}
+		policy := nicinfo.Policy{Interfaces: map[string]nicinfo.InterfacePolicy{name: {Addresses: addresses}}}
+		for _, r := range policy.Check(snap) {
+			if !r.Passed {
+				result.add(pluginCritical, name+" lacks "+strings.TrimPrefix(r.Check, name+" has address "))
+			}
+		}
+		if *argsGateway && len(gateways[name]) == 0 {
+			result.add(pluginCritical, name+" has no default gateway")
+		}
+		summary = append(summary, strings.TrimSpace(name+" "+nic.State()+" "+strings.Join(addressStrings(nic.IPv4), " ")))
+
+		if !rates {
+			continue
+		}
+		current, err := nicinfo.ReadInterfaceStats(name)
+		if err != nil || nic.Stats == nil {
+			result.add(pluginUnknown, name+" counters unavailable")
+			continue
+		}
+		seconds := interval.Seconds()
+		errorRate := float64(counterDelta(nic.Stats.RxErrors, current.RxErrors)+counterDelta(nic.Stats.TxErrors, current.TxErrors)) / seconds
+		dropRate := float64(counterDelta(nic.Stats.RxDropped, current.RxDropped)+counterDelta(nic.Stats.TxDropped, current.TxDropped)) / seconds
+		if state := check(errorRate, warnErrors, critErrors); state != pluginOK {
+			result.add(state, fmt.Sprintf("%s %.2f errors/s", name, errorRate))
+		}
+		if state := check(dropRate, warnDrops, critDrops); state != pluginOK {
+			result.add(state, fmt.Sprintf("%s %.2f drops/s", name, dropRate))
+		}
+		result.perfdata = append(result.perfdata,
+			fmt.Sprintf("'%s_errors'=%.2f;%s;%s;0", name, errorRate, warnErrors.String(), critErrors.String()),
+			fmt.Sprintf("'%s_drops'=%.2f;%s;%s;0", name, dropRate, warnDrops.String(), critDrops.String()),
+			fmt.Sprintf("'%s_rx_bytes'=%dc", name, current.RxBytes),
+			fmt.Sprintf("'%s_tx_bytes'=%dc", name, current.TxBytes))
+	}
+	if len(names) == 0 {
+		result.add(pluginUnknown, "no interfaces to check")
+	}
+
+	message := strings.Join(summary, ", ")
+	if len(result.problems) > 0 {
+		message = strings.Join(result.problems, ", ")
+	}
+	line := pluginStates[result.state] + " - " + message
+	if len(result.perfdata) > 0 {
+		line += " | " + strings.Join(result.perfdata, " ")
+	}
+	fmt.Println(line)
+	return result.state
+}
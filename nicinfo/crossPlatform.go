@@ -12,7 +12,7 @@ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLI
 
 */
 
-package main
+package nicinfo
 
 import (
 	"bytes"
@@ -33,12 +33,3 @@ func timeToString(input uint64) string {
 	slots := strings.Split(raw, " ")
 	return fmt.Sprintf("%s %s", slots[0], slots[1])
 }
-
-func arrayContains(value string, array []string) bool {
-	for _, a := range array {
-		if a == value {
-			return true
-		}
-	}
-	return false
-}
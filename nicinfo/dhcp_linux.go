@@ -0,0 +1,346 @@
+//go:build linux
+// +build linux
+
+/*
+dhcp_linux.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+*/
+
+package nicinfo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lease files of the common DHCP clients; NetworkManager writes dhclient formatted leases when it uses
+// dhclient and networkd formatted ones when it uses its internal client
+var (
+	dhclientLeaseGlobs = []string{"/var/lib/dhcp/*.leases", "/var/lib/dhclient/*.leases", "/var/lib/NetworkManager/*.lease"}
+	dhcpcdLeaseGlobs   = []string{"/var/lib/dhcpcd/*.lease", "/var/lib/dhcpcd5/*.lease", "/var/db/dhcpcd/*.lease"}
+)
+
+const networkdLeaseDir = "/run/systemd/netif/leases"
+
+// linuxLease is a lease read from any of the supported clients; dns are the DNS servers it handed out
+type linuxLease struct {
+	iface   string
+	ip      string
+	server  string
+	dns     []string
+	start   time.Time
+	expires time.Time
+}
+
+// currentLeases returns the current lease of each of allInterfaces; when several clients
+// have a lease for the same interface, the one expiring last is used
+func currentLeases(allInterfaces []Interface) []linuxLease {
+	var found []linuxLease
+	for _, pattern := range dhclientLeaseGlobs {
+		fileNames, _ := filepath.Glob(pattern)
+		for _, fileName := range fileNames {
+			found = append(found, readLeaseFile(fileName, allInterfaces)...)
+		}
+	}
+	for _, pattern := range dhcpcdLeaseGlobs {
+		fileNames, _ := filepath.Glob(pattern)
+		for _, fileName := range fileNames {
+			if lease, ok := readDhcpcdLease(fileName, allInterfaces); ok {
+				found = append(found, lease)
+			}
+		}
+	}
+	for _, nic := range allInterfaces {
+		fileName := filepath.Join(networkdLeaseDir, strconv.Itoa(nic.Index))
+		if lease, ok := readNetworkdLease(fileName, nic.Name); ok {
+			found = append(found, lease)
+		}
+	}
+	return selectLeases(found, allInterfaces, time.Now())
+}
+
+// selectLeases picks the lease expiring last of each of allInterfaces from found; leases that
+// expired before now are left out, since lease files keep them after the client has stopped
+func selectLeases(found []linuxLease, allInterfaces []Interface, now time.Time) []linuxLease {
+	var allLeases []linuxLease
+	for _, nic := range allInterfaces {
+		var current *linuxLease
+		for i, lease := range found {
+			if lease.iface != nic.Name || (!lease.expires.IsZero() && lease.expires.Before(now)) {
+				continue
+			}
+			if current == nil || lease.expires.After(current.expires) {
+				current = &found[i]
+			}
+		}
+		if current != nil {
+			allLeases = append(allLeases, *current)
+		}
+	}
+	return allLeases
+}
+
+// collectDHCPLeases converts the leases returned by currentLeases
+func collectDHCPLeases(allLeases []linuxLease) []DHCPLease {
+	var allDhcpInfo []DHCPLease
+	for _, current := range allLeases {
+		leaseInfo := DHCPLease{Interface: current.iface, IP: current.ip, Server: current.server}
+		if !current.start.IsZero() {
+			leaseInfo.LeaseStart = timeToString(uint64(current.start.Unix()))
+		}
+		if !current.expires.IsZero() {
+			leaseInfo.LeaseExpires = timeToString(uint64(current.expires.Unix()))
+		}
+		if !current.start.IsZero() && !current.expires.IsZero() {
+			seconds := int64(current.expires.Sub(current.start).Seconds())
+			if formatted, err := FormatLeaseTime(strconv.FormatInt(seconds, 10)); err == nil {
+				leaseInfo.LeaseDuration = ShortenLeaseDuration(formatted)
+			}
+		}
+		allDhcpInfo = append(allDhcpInfo, leaseInfo)
+	}
+	return allDhcpInfo
+}
+
+// applyDHCPServers changes the source of the DNS servers of conf that one of allLeases handed out to dhcp
+func applyDHCPServers(conf *Resolver, allLeases []linuxLease) {
+	for i, server := range conf.Servers {
+		for _, lease := range allLeases {
+			if slices.Contains(lease.dns, server.Address) {
+				conf.Servers[i].Source = SourceDHCP
+				break
+			}
+		}
+	}
+}
+
+// leaseFileInterface returns the interface a lease file belongs to, for clients that name
+// their files after it, such as dhcpcd's eth0.lease or NetworkManager's internal-<uuid>-eth0.lease
+func leaseFileInterface(fileName string, allInterfaces []Interface) string {
+	base := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(fileName), ".leases"), ".lease")
+	for _, nic := range allInterfaces {
+		if base == nic.Name || strings.HasPrefix(base, nic.Name+"-") || strings.HasSuffix(base, "-"+nic.Name) {
+			return nic.Name
+		}
+	}
+	return ""
+}
+
+// readLeaseFile reads a dhclient or NetworkManager lease file
+func readLeaseFile(fileName string, allInterfaces []Interface) []linuxLease {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil
+	}
+	if bytes.Contains(content, []byte("lease {")) {
+		return parseDhclientLeases(bytes.NewReader(content))
+	}
+
+	iface := leaseFileInterface(fileName, allInterfaces)
+	if len(iface) == 0 {
+		return nil
+	}
+	if lease, ok := readNetworkdLease(fileName, iface); ok {
+		return []linuxLease{lease}
+	}
+	return nil
+}
+
+/* dhclient lease file, times are in UTC:
+lease {
+  interface "eth0";
+  fixed-address 192.168.1.5;
+  option dhcp-lease-time 86400;
+  option dhcp-server-identifier 192.168.1.1;
+  option domain-name-servers 192.168.1.1,9.9.9.9;
+  renew 2 2025/03/25 10:00:00;
+  expire 3 2025/03/26 04:21:32;
+}
+*/
+
+// parseDhclientLeases returns every lease block of a dhclient formatted file, oldest first
+func parseDhclientLeases(f io.Reader) []linuxLease {
+	var allLeases []linuxLease
+	var lease linuxLease
+	var leaseTime time.Duration
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// statements end with ';', which may be followed by a comment, as in: expire epoch 1742986800; # Wed Mar 26 ...
+		line, _, _ := strings.Cut(scanner.Text(), ";")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch {
+		case fields[0] == "lease":
+			lease = linuxLease{}
+			leaseTime = 0
+		case fields[0] == "}":
+			if !lease.expires.IsZero() && leaseTime > 0 {
+				lease.start = lease.expires.Add(-leaseTime)
+			}
+			allLeases = append(allLeases, lease)
+		case fields[0] == "interface" && len(fields) == 2:
+			lease.iface = strings.Trim(fields[1], `"`)
+		case fields[0] == "fixed-address" && len(fields) == 2:
+			lease.ip = fields[1]
+		case fields[0] == "option" && len(fields) == 3 && fields[1] == "dhcp-server-identifier":
+			lease.server = fields[2]
+		case fields[0] == "option" && len(fields) == 3 && fields[1] == "domain-name-servers":
+			lease.dns = strings.Split(fields[2], ",")
+		case fields[0] == "option" && len(fields) == 3 && fields[1] == "dhcp-lease-time":
+			if seconds, err := strconv.Atoi(fields[2]); err == nil {
+				leaseTime = time.Duration(seconds) * time.Second
+			}
+		case fields[0] == "expire":
+			lease.expires = parseDhclientTime(fields[1:])
+		}
+	}
+	return allLeases
+}
+
+// parseDhclientTime converts "W YYYY/MM/DD HH:MM:SS" in UTC or "epoch N", used with db-time-format local
+func parseDhclientTime(fields []string) time.Time {
+	if len(fields) >= 2 && fields[0] == "epoch" {
+		if seconds, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+			return time.Unix(seconds, 0)
+		}
+		return time.Time{}
+	}
+	if len(fields) >= 3 {
+		if t, err := time.Parse("2006/01/02 15:04:05", fields[1]+" "+fields[2]); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+/* systemd-networkd lease file, named after the interface index:
+ADDRESS=192.168.1.5
+SERVER_ADDRESS=192.168.1.1
+LIFETIME=86400
+DNS=192.168.1.1 9.9.9.9
+*/
+
+// readNetworkdLease reads a systemd-networkd formatted lease; the file is rewritten whenever the
+// lease is acquired or renewed, so its modification time is used as the start of the lease
+func readNetworkdLease(fileName, iface string) (linuxLease, bool) {
+	info, err := os.Stat(fileName)
+	if err != nil {
+		return linuxLease{}, false
+	}
+	values := readStateFile(fileName)
+	if len(values["ADDRESS"]) == 0 {
+		return linuxLease{}, false
+	}
+
+	lease := linuxLease{iface: iface, ip: values["ADDRESS"], server: values["SERVER_ADDRESS"], dns: strings.Fields(values["DNS"]), start: info.ModTime()}
+	if seconds, err := strconv.Atoi(values["LIFETIME"]); err == nil {
+		lease.expires = lease.start.Add(time.Duration(seconds) * time.Second)
+	}
+	return lease, true
+}
+
+// readDhcpcdLease reads a dhcpcd lease, which is the raw DHCP message that was received;
+// its modification time is used as the start of the lease
+func readDhcpcdLease(fileName string, allInterfaces []Interface) (linuxLease, bool) {
+	iface := leaseFileInterface(fileName, allInterfaces)
+	if len(iface) == 0 {
+		return linuxLease{}, false
+	}
+	info, err := os.Stat(fileName)
+	if err != nil {
+		return linuxLease{}, false
+	}
+	message, err := os.ReadFile(fileName)
+	if err != nil {
+		return linuxLease{}, false
+	}
+	reply, ok := parseDHCPMessage(message)
+	if !ok {
+		return linuxLease{}, false
+	}
+
+	lease := linuxLease{iface: iface, ip: reply.ip, server: reply.server, dns: reply.dns, start: info.ModTime()}
+	if reply.leaseTime > 0 {
+		lease.expires = lease.start.Add(time.Duration(reply.leaseTime) * time.Second)
+	}
+	return lease, true
+}
+
+// DHCP options, from RFC 2132
+const (
+	dhcpOptionPad        = 0
+	dhcpOptionDNS        = 6
+	dhcpOptionLeaseTime  = 51
+	dhcpOptionServerID   = 54
+	dhcpOptionEnd        = 255
+	dhcpMessageMinLength = 240
+)
+
+var dhcpMagicCookie = []byte{99, 130, 83, 99}
+
+// dhcpMessage is what parseDHCPMessage extracts from a DHCP message
+type dhcpMessage struct {
+	ip        string
+	server    string
+	dns       []string
+	leaseTime uint32
+}
+
+// parseDHCPMessage extracts the assigned address, server identifier, DNS servers and lease time of a DHCP message
+func parseDHCPMessage(message []byte) (dhcpMessage, bool) {
+	if len(message) < dhcpMessageMinLength || !bytes.Equal(message[236:240], dhcpMagicCookie) {
+		return dhcpMessage{}, false
+	}
+	reply := dhcpMessage{ip: net.IP(message[16:20]).String()}
+
+	options := message[dhcpMessageMinLength:]
+	for i := 0; i < len(options); {
+		code := options[i]
+		if code == dhcpOptionPad {
+			i++
+			continue
+		}
+		if code == dhcpOptionEnd || i+1 >= len(options) {
+			break
+		}
+		length := int(options[i+1])
+		if i+2+length > len(options) {
+			break
+		}
+		value := options[i+2 : i+2+length]
+		switch {
+		case code == dhcpOptionServerID && length == 4:
+			reply.server = net.IP(value).String()
+		case code == dhcpOptionDNS && length%4 == 0:
+			for j := 0; j < length; j += 4 {
+				reply.dns = append(reply.dns, net.IP(value[j:j+4]).String())
+			}
+		case code == dhcpOptionLeaseTime && length == 4:
+			reply.leaseTime = binary.BigEndian.Uint32(value)
+		}
+		i += 2 + length
+	}
+	return reply, true
+}
@@ -0,0 +1,200 @@
+//go:build linux
+// +build linux
+
+/*
+dhcp_linux_test.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*/
+
+package nicinfo
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseDhclientLeases(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "dhcp", "dhclient.leases"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	utc := func(value string) time.Time {
+		parsed, err := time.Parse("2006/01/02 15:04:05", value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+	want := []linuxLease{
+		{
+			iface: "eth0", ip: "192.168.1.5", server: "192.168.1.1", dns: []string{"192.168.1.1", "9.9.9.9"},
+			start: utc("2025/03/25 04:21:32"), expires: utc("2025/03/26 04:21:32"),
+		},
+		{
+			iface: "eth0", ip: "192.168.1.23", server: "192.168.1.1", dns: []string{"192.168.1.1"},
+			start: utc("2025/03/26 10:00:00"), expires: utc("2025/03/26 11:00:00"),
+		},
+		{
+			// written with db-time-format local, without a lease time
+			iface: "wlan0", ip: "10.0.0.7", server: "10.0.0.1",
+			expires: time.Unix(1742986800, 0),
+		},
+	}
+
+	allLeases := parseDhclientLeases(f)
+	if len(allLeases) != len(want) {
+		t.Fatalf("got %d leases, want %d", len(allLeases), len(want))
+	}
+	for i, lease := range allLeases {
+		if lease.iface != want[i].iface || lease.ip != want[i].ip || lease.server != want[i].server ||
+			!reflect.DeepEqual(lease.dns, want[i].dns) || !lease.start.Equal(want[i].start) || !lease.expires.Equal(want[i].expires) {
+			t.Errorf("lease %d:\n got %+v\nwant %+v", i, lease, want[i])
+		}
+	}
+}
+
+func TestReadNetworkdLease(t *testing.T) {
+	fileName := filepath.Join("testdata", "dhcp", "networkd.lease")
+	lease, ok := readNetworkdLease(fileName, "eth0")
+	if !ok {
+		t.Fatalf("%s: no lease", fileName)
+	}
+	if lease.iface != "eth0" || lease.ip != "192.168.1.5" || lease.server != "192.168.1.1" {
+		t.Errorf("got %+v", lease)
+	}
+	if want := []string{"192.168.1.1", "9.9.9.9"}; !reflect.DeepEqual(lease.dns, want) {
+		t.Errorf("dns = %q, want %q", lease.dns, want)
+	}
+	if duration := lease.expires.Sub(lease.start); duration != 24*time.Hour {
+		t.Errorf("lease time = %s, want 24h", duration)
+	}
+}
+
+// newDHCPMessage returns a DHCPACK from 192.168.1.1 that assigns 192.168.1.5, followed by options
+func newDHCPMessage(options ...byte) []byte {
+	message := make([]byte, dhcpMessageMinLength)
+	message[0] = 2 // BOOTREPLY
+	copy(message[16:20], []byte{192, 168, 1, 5})
+	copy(message[236:240], dhcpMagicCookie)
+	return append(message, options...)
+}
+
+func TestParseDHCPMessage(t *testing.T) {
+	serverID := []byte{dhcpOptionServerID, 4, 192, 168, 1, 1}
+	leaseTime := []byte{dhcpOptionLeaseTime, 4, 0, 1, 0x51, 0x80}
+	dns := []byte{dhcpOptionDNS, 8, 192, 168, 1, 1, 9, 9, 9, 9}
+	complete := newDHCPMessage(append(append(append([]byte{53, 1, 5, dhcpOptionPad}, serverID...), leaseTime...), append(dns, dhcpOptionEnd)...)...)
+
+	tests := []struct {
+		name    string
+		message []byte
+		want    dhcpMessage
+		ok      bool
+	}{
+		{
+			name:    "complete",
+			message: complete,
+			want:    dhcpMessage{ip: "192.168.1.5", server: "192.168.1.1", dns: []string{"192.168.1.1", "9.9.9.9"}, leaseTime: 86400},
+			ok:      true,
+		},
+		{
+			name:    "truncated header",
+			message: complete[:200],
+		},
+		{
+			name:    "truncated before the options",
+			message: complete[:dhcpMessageMinLength-1],
+		},
+		{
+			name:    "truncated in an option",
+			message: newDHCPMessage(append(append([]byte{}, serverID...), dns[:5]...)...),
+			want:    dhcpMessage{ip: "192.168.1.5", server: "192.168.1.1"},
+			ok:      true,
+		},
+		{
+			name:    "truncated after an option code",
+			message: newDHCPMessage(append(append([]byte{}, serverID...), dhcpOptionLeaseTime)...),
+			want:    dhcpMessage{ip: "192.168.1.5", server: "192.168.1.1"},
+			ok:      true,
+		},
+		{
+			name:    "options after the end are ignored",
+			message: newDHCPMessage(append([]byte{dhcpOptionEnd}, serverID...)...),
+			want:    dhcpMessage{ip: "192.168.1.5"},
+			ok:      true,
+		},
+		{
+			name:    "invalid option lengths",
+			message: newDHCPMessage(dhcpOptionServerID, 2, 192, 168, dhcpOptionDNS, 3, 9, 9, 9, dhcpOptionEnd),
+			want:    dhcpMessage{ip: "192.168.1.5"},
+			ok:      true,
+		},
+		{
+			name:    "not a DHCP message",
+			message: append(append([]byte{}, complete[:236]...), append([]byte{0, 0, 0, 0}, complete[240:]...)...),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseDHCPMessage(tt.message)
+			if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, %v; want %+v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestApplyDHCPServers(t *testing.T) {
+	conf := Resolver{Servers: dnsServers([]string{"192.168.1.1", "1.1.1.1"}, SourceResolvConf, "")}
+	applyDHCPServers(&conf, []linuxLease{{iface: "eth0", dns: []string{"192.168.1.1", "9.9.9.9"}}})
+
+	want := []DNSServer{
+		{Address: "192.168.1.1", Family: "ipv4", Source: SourceDHCP},
+		{Address: "1.1.1.1", Family: "ipv4", Source: SourceResolvConf},
+	}
+	if !reflect.DeepEqual(conf.Servers, want) {
+		t.Errorf("got %+v, want %+v", conf.Servers, want)
+	}
+}
+
+func TestSelectLeases(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "dhcp", "dhclient-expired.leases"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	found := parseDhclientLeases(f)
+	allInterfaces := []Interface{{Name: "eth0"}, {Name: "wlan0"}}
+
+	// eth0 only has an expired lease, wlan0 an expired and a current one
+	allLeases := selectLeases(found, allInterfaces, time.Now())
+	if len(allLeases) != 1 || allLeases[0].iface != "wlan0" || allLeases[0].ip != "10.0.0.8" {
+		t.Fatalf("got %+v, want only the current lease of wlan0", allLeases)
+	}
+
+	conf := Resolver{Servers: dnsServers([]string{"192.168.1.1", "10.0.0.1"}, SourceResolvConf, "")}
+	applyDHCPServers(&conf, allLeases)
+	if conf.Servers[0].Source != SourceResolvConf || conf.Servers[1].Source != SourceDHCP {
+		t.Errorf("got %+v, want only 10.0.0.1 from dhcp", conf.Servers)
+	}
+
+	// a lease without an expiry can't be told apart from a current one
+	allLeases = selectLeases([]linuxLease{{iface: "eth0", ip: "192.168.1.5"}}, allInterfaces, time.Now())
+	if len(allLeases) != 1 {
+		t.Errorf("got %+v, want the lease without an expiry", allLeases)
+	}
+}
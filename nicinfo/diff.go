@@ -0,0 +1,190 @@
+/*
+diff.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*/
+
+package nicinfo
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Fields compared by Compare; FieldDNS and FieldSearch are not tied to an interface
+const (
+	FieldState   = "state"
+	FieldIPv4    = "ipv4"
+	FieldIPv6    = "ipv6"
+	FieldGateway = "gateway"
+	FieldMAC     = "mac"
+	FieldMTU     = "mtu"
+	FieldFlags   = "flags"
+	FieldDNS     = "dns"
+	FieldSearch  = "search"
+)
+
+// Interface states reported in FieldState changes
+const (
+	StateUp     = "up"
+	StateDown   = "down"
+	StateAbsent = "absent"
+)
+
+// addressFields are still compared when an interface appears or disappears
+var addressFields = []string{FieldIPv4, FieldIPv6, FieldGateway}
+
+// Change is one field that differs between two snapshots; lists are space separated
+type Change struct {
+	Interface string `json:"interface,omitempty"`
+	Field     string `json:"field"`
+	Old       string `json:"old"`
+	New       string `json:"new"`
+}
+
+// String describes the change, such as: eth0 ipv4 10.0.0.2/24 -> 10.0.0.3/24
+func (c Change) String() string {
+	oldValue, newValue := c.Old, c.New
+	if len(oldValue) == 0 {
+		oldValue = "(none)"
+	}
+	if len(newValue) == 0 {
+		newValue = "(none)"
+	}
+	if len(c.Interface) == 0 {
+		return c.Field + " " + oldValue + " -> " + newValue
+	}
+	return c.Interface + " " + c.Field + " " + oldValue + " -> " + newValue
+}
+
+// State returns up when the interface is both administratively up and running, otherwise down
+func (nic Interface) State() string {
+	if slices.Contains(nic.Flags, "up") && slices.Contains(nic.Flags, "running") {
+		return StateUp
+	}
+	return StateDown
+}
+
+// Compare returns every change from old to current: per interface, in name order, its state, addresses,
+// default gateways, MAC address, MTU and flags, followed by the DNS servers and search domains.
+// An interface that only exists in one of the snapshots has the state absent in the other, and
+// its addresses and gateways are compared with none, so that a recreated VPN or tun device
+// reports its new addresses; its MAC address, MTU and flags are left out.
+func Compare(old, current *Snapshot) []Change {
+	oldInterfaces, newInterfaces := interfaceFields(old), interfaceFields(current)
+	names := slices.Sorted(maps.Keys(oldInterfaces))
+	for name := range newInterfaces {
+		if _, ok := oldInterfaces[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	var changes []Change
+	fields := []string{FieldState, FieldIPv4, FieldIPv6, FieldGateway, FieldMAC, FieldMTU, FieldFlags}
+	for _, name := range names {
+		before, after := oldInterfaces[name], newInterfaces[name]
+		for _, field := range fields {
+			oldValue, newValue := before[field], after[field]
+			if field == FieldState {
+				if before == nil {
+					oldValue = StateAbsent
+				}
+				if after == nil {
+					newValue = StateAbsent
+				}
+			} else if (before == nil || after == nil) && !slices.Contains(addressFields, field) {
+				// the state change already says that the interface appeared or disappeared
+				continue
+			}
+			if oldValue != newValue {
+				changes = append(changes, Change{Interface: name, Field: field, Old: oldValue, New: newValue})
+			}
+		}
+	}
+
+	oldServers, newServers := dnsServerList(old), dnsServerList(current)
+	if oldServers != newServers {
+		changes = append(changes, Change{Field: FieldDNS, Old: oldServers, New: newServers})
+	}
+	oldSearch, newSearch := strings.Join(old.Resolver.Search, " "), strings.Join(current.Resolver.Search, " ")
+	if oldSearch != newSearch {
+		changes = append(changes, Change{Field: FieldSearch, Old: oldSearch, New: newSearch})
+	}
+	return changes
+}
+
+// interfaceFields maps each interface name to the values of the fields compared by Compare
+func interfaceFields(snap *Snapshot) map[string]map[string]string {
+	gateways := make(map[string][]string)
+	for _, r := range snap.DefaultRoutes() {
+		if !slices.Contains(gateways[r.Interface], r.Gateway) {
+			gateways[r.Interface] = append(gateways[r.Interface], r.Gateway)
+		}
+	}
+
+	result := make(map[string]map[string]string)
+	for _, nic := range snap.Interfaces {
+		result[nic.Name] = map[string]string{
+			FieldState:   nic.State(),
+			FieldIPv4:    strings.Join(addressStrings(nic.IPv4), " "),
+			FieldIPv6:    strings.Join(addressStrings(nic.IPv6), " "),
+			FieldGateway: strings.Join(gateways[nic.Name], " "),
+			FieldMAC:     nic.MAC,
+			FieldMTU:     strconv.Itoa(nic.MTU),
+			FieldFlags:   strings.Join(nic.Flags, " "),
+		}
+	}
+	return result
+}
+
+// dnsServerList returns the addresses of the DNS servers, each one once
+func dnsServerList(snap *Snapshot) string {
+	var servers []string
+	for _, server := range snap.Resolver.Servers {
+		if !slices.Contains(servers, server.Address) {
+			servers = append(servers, server.Address)
+		}
+	}
+	return strings.Join(servers, " ")
+}
+
+// Selection returns the Options that select the same interfaces as snap did, so that a live
+// snapshot can be compared with a saved one
+func (snap *Snapshot) Selection() Options {
+	return Options{Brief: snap.Brief, Interface: snap.SelectedInterface}
+}
+
+// SameSelection reports whether old and current were collected with the same Options.Brief and
+// Options.Interface; otherwise Compare reports the interfaces that only one of them selected
+func SameSelection(old, current *Snapshot) bool {
+	return old.Brief == current.Brief && strings.EqualFold(old.SelectedInterface, current.SelectedInterface)
+}
+
+// ReadSnapshot loads a snapshot that was saved as JSON, such as with: nics snapshot save or nics -a -o json
+func ReadSnapshot(fileName string) (*Snapshot, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("%s: %w", fileName, err)
+	}
+	if snap.SchemaVersion == 0 || snap.SchemaVersion > SchemaVersion {
+		return nil, fmt.Errorf("%s: unsupported schema_version %d", fileName, snap.SchemaVersion)
+	}
+	return &snap, nil
+}
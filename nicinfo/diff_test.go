@@ -0,0 +1,154 @@
+/*
+diff_test.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*/
+
+package nicinfo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	running := []string{"up", "broadcast", "running"}
+	eth0 := Interface{Name: "eth0", MAC: "02:00:00:00:00:01", MTU: 1500, Flags: running,
+		IPv4: []Address{{"192.0.2.2", 24}}, IPv6: []Address{{"fe80::2", 64}}}
+	gateway := Route{Destination: "0.0.0.0/0", Gateway: "192.0.2.1", Interface: "eth0", Flags: []string{"up", "gateway"}}
+	base := Snapshot{
+		Interfaces: []Interface{eth0},
+		Routes:     []Route{gateway},
+		Resolver:   Resolver{Servers: dnsServers([]string{"192.0.2.53"}, SourceResolvConf, ""), Search: []string{"example.com"}},
+	}
+
+	// changed returns a copy of base with eth0 and the rest modified by change
+	changed := func(change func(snap *Snapshot, nic *Interface)) *Snapshot {
+		snap := base
+		nic := eth0
+		snap.Routes = append([]Route{}, base.Routes...)
+		change(&snap, &nic)
+		snap.Interfaces = append([]Interface{nic}, snap.Interfaces[1:]...)
+		return &snap
+	}
+
+	tests := []struct {
+		name    string
+		current *Snapshot
+		want    []Change
+	}{
+		{
+			name:    "nothing changed",
+			current: changed(func(snap *Snapshot, nic *Interface) {}),
+		},
+		{
+			name: "link down",
+			current: changed(func(snap *Snapshot, nic *Interface) {
+				nic.Flags = []string{"up", "broadcast"}
+			}),
+			want: []Change{
+				{Interface: "eth0", Field: FieldState, Old: StateUp, New: StateDown},
+				{Interface: "eth0", Field: FieldFlags, Old: "up broadcast running", New: "up broadcast"},
+			},
+		},
+		{
+			name: "address added and removed",
+			current: changed(func(snap *Snapshot, nic *Interface) {
+				nic.IPv4 = []Address{{"192.0.2.3", 24}}
+				nic.IPv6 = []Address{{"fe80::2", 64}, {"2001:db8::2", 64}}
+			}),
+			want: []Change{
+				{Interface: "eth0", Field: FieldIPv4, Old: "192.0.2.2/24", New: "192.0.2.3/24"},
+				{Interface: "eth0", Field: FieldIPv6, Old: "fe80::2/64", New: "fe80::2/64 2001:db8::2/64"},
+			},
+		},
+		{
+			name: "gateway, MAC and MTU",
+			current: changed(func(snap *Snapshot, nic *Interface) {
+				snap.Routes[0].Gateway = "192.0.2.254"
+				nic.MAC = "02:00:00:00:00:02"
+				nic.MTU = 9000
+			}),
+			want: []Change{
+				{Interface: "eth0", Field: FieldGateway, Old: "192.0.2.1", New: "192.0.2.254"},
+				{Interface: "eth0", Field: FieldMAC, Old: "02:00:00:00:00:01", New: "02:00:00:00:00:02"},
+				{Interface: "eth0", Field: FieldMTU, Old: "1500", New: "9000"},
+			},
+		},
+		{
+			name: "default route that is down",
+			current: changed(func(snap *Snapshot, nic *Interface) {
+				snap.Routes[0].Flags = []string{"gateway"}
+			}),
+			want: []Change{{Interface: "eth0", Field: FieldGateway, Old: "192.0.2.1"}},
+		},
+		{
+			name: "DNS servers and search domains",
+			current: changed(func(snap *Snapshot, nic *Interface) {
+				snap.Resolver = Resolver{
+					Servers: append(dnsServers([]string{"192.0.2.53"}, SourceResolvConf, ""), dnsServers([]string{"192.0.2.53", "2001:db8::53"}, SourceResolved, "eth0")...),
+				}
+			}),
+			want: []Change{
+				{Field: FieldDNS, Old: "192.0.2.53", New: "192.0.2.53 2001:db8::53"},
+				{Field: FieldSearch, Old: "example.com"},
+			},
+		},
+		{
+			name: "interface appeared",
+			current: changed(func(snap *Snapshot, nic *Interface) {
+				snap.Interfaces = append(snap.Interfaces, Interface{Name: "tun0", MTU: 1420, Flags: running, IPv4: []Address{{"10.8.0.6", 32}}})
+				snap.Routes = append(snap.Routes, Route{Destination: "0.0.0.0/0", Gateway: "10.8.0.5", Interface: "tun0", Flags: []string{"up", "gateway"}})
+			}),
+			want: []Change{
+				{Interface: "tun0", Field: FieldState, Old: StateAbsent, New: StateUp},
+				{Interface: "tun0", Field: FieldIPv4, New: "10.8.0.6/32"},
+				{Interface: "tun0", Field: FieldGateway, New: "10.8.0.5"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if changes := Compare(&base, tt.current); !reflect.DeepEqual(changes, tt.want) {
+				t.Errorf("got %+v\nwant %+v", changes, tt.want)
+			}
+		})
+	}
+
+	// the reverse of an interface appearing
+	removed := Compare(tests[len(tests)-1].current, &base)
+	want := []Change{
+		{Interface: "tun0", Field: FieldState, Old: StateUp, New: StateAbsent},
+		{Interface: "tun0", Field: FieldIPv4, Old: "10.8.0.6/32"},
+		{Interface: "tun0", Field: FieldGateway, Old: "10.8.0.5"},
+	}
+	if !reflect.DeepEqual(removed, want) {
+		t.Errorf("interface disappeared: got %+v\nwant %+v", removed, want)
+	}
+}
+
+func TestSameSelection(t *testing.T) {
+	tests := []struct {
+		old, current Snapshot
+		want         bool
+	}{
+		{Snapshot{}, Snapshot{}, true},
+		{Snapshot{Brief: true}, Snapshot{Brief: true}, true},
+		{Snapshot{Brief: true}, Snapshot{}, false},
+		{Snapshot{SelectedInterface: "eth0"}, Snapshot{SelectedInterface: "ETH0"}, true},
+		{Snapshot{SelectedInterface: "eth0"}, Snapshot{}, false},
+	}
+	for _, tt := range tests {
+		if got := SameSelection(&tt.old, &tt.current); got != tt.want {
+			t.Errorf("SameSelection(%+v, %+v) = %v, want %v", tt.old, tt.current, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+/*
+events.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*/
+
+package nicinfo
+
+import (
+	"fmt"
+	"time"
+)
+
+// Event types
+const (
+	EventLink    = "link"
+	EventAddress = "address"
+	EventRoute   = "route"
+)
+
+// Event actions; links go up and down, everything can be added and removed
+const (
+	ActionAdded   = "added"
+	ActionRemoved = "removed"
+	ActionUp      = "up"
+	ActionDown    = "down"
+)
+
+// Event is a single change to an interface, address or route, as reported by WatchEvents
+type Event struct {
+	Time        time.Time `json:"time"`
+	Type        string    `json:"type"`
+	Action      string    `json:"action"`
+	Interface   string    `json:"interface"`
+	Address     string    `json:"address,omitempty"`
+	Destination string    `json:"destination,omitempty"`
+	Gateway     string    `json:"gateway,omitempty"`
+	Metric      int64     `json:"metric,omitempty"`
+}
+
+// String describes the event in a few words, such as: eth0 down, wlan0 +192.168.1.5/24,
+// default route via 10.0.0.1 on eth0 removed
+func (e Event) String() string {
+	switch e.Type {
+	case EventAddress:
+		if e.Action == ActionRemoved {
+			return fmt.Sprintf("%s -%s", e.Interface, e.Address)
+		}
+		return fmt.Sprintf("%s +%s", e.Interface, e.Address)
+	case EventRoute:
+		destination := "route " + e.Destination
+		if e.Destination == "0.0.0.0/0" || e.Destination == "::/0" {
+			destination = "default route"
+		}
+		if len(e.Gateway) > 0 {
+			return fmt.Sprintf("%s via %s on %s %s", destination, e.Gateway, e.Interface, e.Action)
+		}
+		return fmt.Sprintf("%s on %s %s", destination, e.Interface, e.Action)
+	}
+	return fmt.Sprintf("%s %s", e.Interface, e.Action)
+}
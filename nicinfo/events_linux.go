@@ -0,0 +1,247 @@
+//go:build linux
+// +build linux
+
+/*
+events_linux.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*/
+
+package nicinfo
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// rtnetlink multicast groups from linux/rtnetlink.h, the syscall package does not define them
+const (
+	rtmgrpLink       = 0x1
+	rtmgrpIPv4IfAddr = 0x10
+	rtmgrpIPv4Route  = 0x40
+	rtmgrpIPv6IfAddr = 0x100
+	rtmgrpIPv6Route  = 0x400
+)
+
+// eventGroups are the multicast groups that WatchEvents subscribes to
+const eventGroups = rtmgrpLink | rtmgrpIPv4IfAddr | rtmgrpIPv6IfAddr | rtmgrpIPv4Route | rtmgrpIPv6Route
+
+// rtmFCloned marks IPv6 routes that were cloned into the route cache, these are not configuration changes
+const rtmFCloned = 0x200
+
+// linkState is what WatchEvents remembers of each link to tell an up/down transition apart from
+// any other change reported by RTM_NEWLINK
+type linkState struct {
+	name    string
+	running bool
+}
+
+// WatchEvents subscribes to rtnetlink and calls handle for every link, address and route change until
+// ctx is done; routes of the local table, which the kernel adds for each address, are not reported
+func WatchEvents(ctx context.Context, handle func(Event)) error {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC|syscall.SOCK_NONBLOCK, syscall.NETLINK_ROUTE)
+	if err != nil {
+		return fmt.Errorf("netlink socket: %w", err)
+	}
+	if err := syscall.Bind(fd, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK, Groups: eventGroups}); err != nil {
+		_ = syscall.Close(fd)
+		return fmt.Errorf("netlink bind: %w", err)
+	}
+	// a non-blocking descriptor is handled by the runtime poller, so closing the file interrupts Read
+	sock := os.NewFile(uintptr(fd), "netlink")
+	defer sock.Close()
+	go func() {
+		<-ctx.Done()
+		_ = sock.Close()
+	}()
+
+	links := make(map[int]linkState)
+	if allInterfaces, err := net.Interfaces(); err == nil {
+		for _, iface := range allInterfaces {
+			links[iface.Index] = linkState{iface.Name, iface.Flags&net.FlagRunning != 0}
+		}
+	}
+
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := sock.Read(buf)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if errors.Is(err, syscall.ENOBUFS) {
+			// the kernel dropped messages because they were not read fast enough; keep going
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("netlink read: %w", err)
+		}
+		messages, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			continue
+		}
+		now := time.Now()
+		for _, m := range messages {
+			if event, ok := parseEvent(&m, links); ok {
+				event.Time = now
+				handle(event)
+			}
+		}
+	}
+}
+
+// eventHeaderSizes are the fixed size headers of the messages that parseEvent handles
+var eventHeaderSizes = map[uint16]int{
+	syscall.RTM_NEWLINK:  syscall.SizeofIfInfomsg,
+	syscall.RTM_DELLINK:  syscall.SizeofIfInfomsg,
+	syscall.RTM_NEWADDR:  syscall.SizeofIfAddrmsg,
+	syscall.RTM_DELADDR:  syscall.SizeofIfAddrmsg,
+	syscall.RTM_NEWROUTE: syscall.SizeofRtMsg,
+	syscall.RTM_DELROUTE: syscall.SizeofRtMsg,
+}
+
+// parseEvent converts one rtnetlink message into an Event, keeping links up to date;
+// ok is false for messages that do not describe a change worth reporting
+func parseEvent(m *syscall.NetlinkMessage, links map[int]linkState) (Event, bool) {
+	// ParseNetlinkRouteAttr panics on a message that is shorter than its fixed size header
+	if size, ok := eventHeaderSizes[m.Header.Type]; !ok || len(m.Data) < size {
+		return Event{}, false
+	}
+	attrs, err := syscall.ParseNetlinkRouteAttr(m)
+	if err != nil {
+		return Event{}, false
+	}
+	switch m.Header.Type {
+	case syscall.RTM_NEWLINK, syscall.RTM_DELLINK:
+		index := int(int32(binary.NativeEndian.Uint32(m.Data[4:8])))
+		flags := binary.NativeEndian.Uint32(m.Data[8:12])
+		state := linkState{links[index].name, flags&syscall.IFF_RUNNING != 0}
+		for _, a := range attrs {
+			if a.Attr.Type == syscall.IFLA_IFNAME {
+				state.name = attrString(a.Value)
+			}
+		}
+		event := Event{Type: EventLink, Interface: state.name}
+		previous, known := links[index]
+		switch {
+		case m.Header.Type == syscall.RTM_DELLINK:
+			delete(links, index)
+			event.Action = ActionRemoved
+			return event, true
+		case !known:
+			event.Action = ActionAdded
+		case previous.running != state.running && state.running:
+			event.Action = ActionUp
+		case previous.running != state.running:
+			event.Action = ActionDown
+		}
+		links[index] = state
+		return event, len(event.Action) > 0
+
+	case syscall.RTM_NEWADDR, syscall.RTM_DELADDR:
+		prefixLen := int(m.Data[1])
+		index := int(binary.NativeEndian.Uint32(m.Data[4:8]))
+		var address, local []byte
+		for _, a := range attrs {
+			switch a.Attr.Type {
+			case syscall.IFA_ADDRESS:
+				address = a.Value
+			case syscall.IFA_LOCAL:
+				local = a.Value
+			}
+		}
+		// on point-to-point links IFA_ADDRESS is the peer, IFA_LOCAL is our own address
+		if local != nil {
+			address = local
+		}
+		ip, ok := netip.AddrFromSlice(address)
+		if !ok {
+			return Event{}, false
+		}
+		event := Event{Type: EventAddress, Action: ActionAdded, Interface: linkName(links, index),
+			Address: netip.PrefixFrom(ip.Unmap(), prefixLen).String()}
+		if m.Header.Type == syscall.RTM_DELADDR {
+			event.Action = ActionRemoved
+		}
+		return event, true
+
+	case syscall.RTM_NEWROUTE, syscall.RTM_DELROUTE:
+		family, dstLen, table := m.Data[0], int(m.Data[1]), int(m.Data[4])
+		flags := binary.NativeEndian.Uint32(m.Data[8:12])
+		if flags&rtmFCloned != 0 {
+			return Event{}, false
+		}
+		var destination netip.Addr
+		var event Event
+		for _, a := range attrs {
+			switch a.Attr.Type {
+			case syscall.RTA_DST:
+				destination, _ = netip.AddrFromSlice(a.Value)
+			case syscall.RTA_GATEWAY:
+				if gateway, ok := netip.AddrFromSlice(a.Value); ok {
+					event.Gateway = gateway.String()
+				}
+			case syscall.RTA_OIF:
+				if len(a.Value) >= 4 {
+					event.Interface = linkName(links, int(binary.NativeEndian.Uint32(a.Value)))
+				}
+			case syscall.RTA_PRIORITY:
+				if len(a.Value) >= 4 {
+					event.Metric = int64(binary.NativeEndian.Uint32(a.Value))
+				}
+			case syscall.RTA_TABLE:
+				if len(a.Value) >= 4 {
+					table = int(binary.NativeEndian.Uint32(a.Value))
+				}
+			}
+		}
+		if table == syscall.RT_TABLE_LOCAL {
+			return Event{}, false
+		}
+		if !destination.IsValid() {
+			destination = netip.IPv4Unspecified()
+			if family == syscall.AF_INET6 {
+				destination = netip.IPv6Unspecified()
+			}
+		}
+		event.Type = EventRoute
+		event.Destination = netip.PrefixFrom(destination, dstLen).String()
+		event.Action = ActionAdded
+		if m.Header.Type == syscall.RTM_DELROUTE {
+			event.Action = ActionRemoved
+		}
+		return event, true
+	}
+	return Event{}, false
+}
+
+// linkName returns the name of the interface with the given index, asking the kernel for
+// interfaces that appeared before WatchEvents saw them
+func linkName(links map[int]linkState, index int) string {
+	if state, ok := links[index]; ok {
+		return state.name
+	}
+	if iface, err := net.InterfaceByIndex(index); err == nil {
+		return iface.Name
+	}
+	return fmt.Sprintf("if%d", index)
+}
+
+// attrString returns a NUL terminated netlink attribute as a string
+func attrString(value []byte) string {
+	return strings.TrimRight(string(value), "\x00")
+}
@@ -0,0 +1,192 @@
+//go:build linux
+// +build linux
+
+/*
+events_linux_test.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*/
+
+package nicinfo
+
+import (
+	"encoding/binary"
+	"net/netip"
+	"syscall"
+	"testing"
+)
+
+// rtAttr encodes a single rtnetlink attribute, padded to a multiple of 4 bytes
+func rtAttr(attrType uint16, value []byte) []byte {
+	b := make([]byte, syscall.SizeofRtAttr, syscall.SizeofRtAttr+len(value)+3)
+	binary.NativeEndian.PutUint16(b[0:2], uint16(syscall.SizeofRtAttr+len(value)))
+	binary.NativeEndian.PutUint16(b[2:4], attrType)
+	b = append(b, value...)
+	for len(b)%4 != 0 {
+		b = append(b, 0)
+	}
+	return b
+}
+
+func attrUint32(attrType uint16, value uint32) []byte {
+	return rtAttr(attrType, binary.NativeEndian.AppendUint32(nil, value))
+}
+
+func attrAddr(attrType uint16, addr string) []byte {
+	return rtAttr(attrType, netip.MustParseAddr(addr).AsSlice())
+}
+
+func netlinkMessage(msgType uint16, header []byte, attrs ...[]byte) *syscall.NetlinkMessage {
+	m := &syscall.NetlinkMessage{Header: syscall.NlMsghdr{Type: msgType}, Data: header}
+	for _, a := range attrs {
+		m.Data = append(m.Data, a...)
+	}
+	return m
+}
+
+// linkMessage is an RTM_NEWLINK or RTM_DELLINK with an ifinfomsg header
+func linkMessage(msgType uint16, index int, flags uint32, attrs ...[]byte) *syscall.NetlinkMessage {
+	header := make([]byte, syscall.SizeofIfInfomsg)
+	binary.NativeEndian.PutUint32(header[4:8], uint32(index))
+	binary.NativeEndian.PutUint32(header[8:12], flags)
+	return netlinkMessage(msgType, header, attrs...)
+}
+
+// addrMessage is an RTM_NEWADDR or RTM_DELADDR with an ifaddrmsg header
+func addrMessage(msgType uint16, family byte, prefixLen byte, index int, attrs ...[]byte) *syscall.NetlinkMessage {
+	header := make([]byte, syscall.SizeofIfAddrmsg)
+	header[0], header[1] = family, prefixLen
+	binary.NativeEndian.PutUint32(header[4:8], uint32(index))
+	return netlinkMessage(msgType, header, attrs...)
+}
+
+// routeMessage is an RTM_NEWROUTE or RTM_DELROUTE with an rtmsg header
+func routeMessage(msgType uint16, family, dstLen, table byte, flags uint32, attrs ...[]byte) *syscall.NetlinkMessage {
+	header := make([]byte, syscall.SizeofRtMsg)
+	header[0], header[1], header[4] = family, dstLen, table
+	binary.NativeEndian.PutUint32(header[8:12], flags)
+	return netlinkMessage(msgType, header, attrs...)
+}
+
+func TestParseEvent(t *testing.T) {
+	const up, running = syscall.IFF_UP, syscall.IFF_UP | syscall.IFF_RUNNING
+	eth0 := rtAttr(syscall.IFLA_IFNAME, []byte("eth0\x00"))
+
+	// the messages are parsed in order, sharing links; eth0 starts out running
+	links := map[int]linkState{2: {"eth0", true}}
+	tests := []struct {
+		name    string
+		message *syscall.NetlinkMessage
+		want    Event
+		ok      bool
+	}{
+		{
+			name:    "link down",
+			message: linkMessage(syscall.RTM_NEWLINK, 2, up, eth0),
+			want:    Event{Type: EventLink, Action: ActionDown, Interface: "eth0"},
+			ok:      true,
+		},
+		{
+			name:    "link up",
+			message: linkMessage(syscall.RTM_NEWLINK, 2, running, eth0),
+			want:    Event{Type: EventLink, Action: ActionUp, Interface: "eth0"},
+			ok:      true,
+		},
+		{
+			name:    "link changed without a transition",
+			message: linkMessage(syscall.RTM_NEWLINK, 2, running, eth0, attrUint32(syscall.IFLA_MTU, 9000)),
+		},
+		{
+			name:    "link added",
+			message: linkMessage(syscall.RTM_NEWLINK, 7, running, rtAttr(syscall.IFLA_IFNAME, []byte("tun0\x00"))),
+			want:    Event{Type: EventLink, Action: ActionAdded, Interface: "tun0"},
+			ok:      true,
+		},
+		{
+			name:    "link removed",
+			message: linkMessage(syscall.RTM_DELLINK, 7, 0),
+			want:    Event{Type: EventLink, Action: ActionRemoved, Interface: "tun0"},
+			ok:      true,
+		},
+		{
+			name:    "IPv4 address added",
+			message: addrMessage(syscall.RTM_NEWADDR, syscall.AF_INET, 24, 2, attrAddr(syscall.IFA_ADDRESS, "192.0.2.5")),
+			want:    Event{Type: EventAddress, Action: ActionAdded, Interface: "eth0", Address: "192.0.2.5/24"},
+			ok:      true,
+		},
+		{
+			name: "point-to-point address added",
+			message: addrMessage(syscall.RTM_NEWADDR, syscall.AF_INET, 32, 2,
+				attrAddr(syscall.IFA_ADDRESS, "10.8.0.5"), attrAddr(syscall.IFA_LOCAL, "10.8.0.6")),
+			want: Event{Type: EventAddress, Action: ActionAdded, Interface: "eth0", Address: "10.8.0.6/32"},
+			ok:   true,
+		},
+		{
+			name:    "IPv6 address removed",
+			message: addrMessage(syscall.RTM_DELADDR, syscall.AF_INET6, 64, 2, attrAddr(syscall.IFA_ADDRESS, "2001:db8::5")),
+			want:    Event{Type: EventAddress, Action: ActionRemoved, Interface: "eth0", Address: "2001:db8::5/64"},
+			ok:      true,
+		},
+		{
+			name: "default route added",
+			message: routeMessage(syscall.RTM_NEWROUTE, syscall.AF_INET, 0, syscall.RT_TABLE_MAIN, 0,
+				attrAddr(syscall.RTA_GATEWAY, "192.0.2.1"), attrUint32(syscall.RTA_OIF, 2), attrUint32(syscall.RTA_PRIORITY, 100)),
+			want: Event{Type: EventRoute, Action: ActionAdded, Interface: "eth0", Destination: "0.0.0.0/0", Gateway: "192.0.2.1", Metric: 100},
+			ok:   true,
+		},
+		{
+			name: "local table route skipped",
+			message: routeMessage(syscall.RTM_NEWROUTE, syscall.AF_INET, 32, syscall.RT_TABLE_LOCAL, 0,
+				attrAddr(syscall.RTA_DST, "192.0.2.5"), attrUint32(syscall.RTA_OIF, 2)),
+		},
+		{
+			name: "local table in RTA_TABLE skipped",
+			message: routeMessage(syscall.RTM_NEWROUTE, syscall.AF_INET, 32, syscall.RT_TABLE_COMPAT, 0,
+				attrAddr(syscall.RTA_DST, "192.0.2.5"), attrUint32(syscall.RTA_TABLE, syscall.RT_TABLE_LOCAL)),
+		},
+		{
+			name: "cloned route skipped",
+			message: routeMessage(syscall.RTM_NEWROUTE, syscall.AF_INET6, 128, syscall.RT_TABLE_MAIN, rtmFCloned,
+				attrAddr(syscall.RTA_DST, "2001:db8::9"), attrUint32(syscall.RTA_OIF, 2)),
+		},
+		{
+			name: "IPv6 route removed",
+			message: routeMessage(syscall.RTM_DELROUTE, syscall.AF_INET6, 64, syscall.RT_TABLE_MAIN, 0,
+				attrAddr(syscall.RTA_DST, "2001:db8::"), attrUint32(syscall.RTA_OIF, 2)),
+			want: Event{Type: EventRoute, Action: ActionRemoved, Interface: "eth0", Destination: "2001:db8::/64"},
+			ok:   true,
+		},
+		{
+			name: "IPv6 default route removed",
+			message: routeMessage(syscall.RTM_DELROUTE, syscall.AF_INET6, 0, syscall.RT_TABLE_MAIN, 0,
+				attrAddr(syscall.RTA_GATEWAY, "fe80::1"), attrUint32(syscall.RTA_OIF, 2)),
+			want: Event{Type: EventRoute, Action: ActionRemoved, Interface: "eth0", Destination: "::/0", Gateway: "fe80::1"},
+			ok:   true,
+		},
+		{
+			name:    "truncated message",
+			message: netlinkMessage(syscall.RTM_NEWLINK, make([]byte, syscall.SizeofIfInfomsg-1)),
+		},
+		{
+			name:    "other message type",
+			message: netlinkMessage(syscall.RTM_NEWNEIGH, make([]byte, 64)),
+		},
+	}
+
+	for _, tt := range tests {
+		event, ok := parseEvent(tt.message, links)
+		if ok != tt.ok || (ok && event != tt.want) {
+			t.Errorf("%s: got %+v, %v; want %+v, %v", tt.name, event, ok, tt.want, tt.ok)
+		}
+	}
+	if _, ok := links[7]; ok {
+		t.Errorf("tun0 is still known after it was removed")
+	}
+}
@@ -0,0 +1,113 @@
+/*
+lease.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+*/
+
+package nicinfo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FormatLeaseTime converts lease time in seconds to a human-readable format
+// showing days, hours, minutes, and seconds.
+func FormatLeaseTime(secondsStr string) (string, error) {
+	// Convert string to integer
+	seconds, err := strconv.ParseInt(secondsStr, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse lease time: %v", err)
+	}
+
+	// Calculate days, hours, minutes, seconds
+	days := seconds / (24 * 60 * 60)
+	seconds %= 24 * 60 * 60
+
+	hours := seconds / (60 * 60)
+	seconds %= 60 * 60
+
+	minutes := seconds / 60
+	seconds %= 60
+
+	// Format the result
+	return fmt.Sprintf("%d days, %d hrs, %d mins, %d secs", days, hours, minutes, seconds), nil
+}
+
+// FormatWithCorrectPlurals ensures time units use singular form when the value is 1.
+// For example:
+// - "1 days" becomes "1 day"
+// - "2 mins" remains "2 mins"
+// - "0 hrs, 1 mins" becomes "0 hrs, 1 min"
+//
+// The function handles days, hrs, mins, and secs units.
+func FormatWithCorrectPlurals(duration string) string {
+	// Replace "1 days" with "1 day"
+	duration = strings.Replace(duration, "1 days", "1 day", 1)
+
+	// Replace "1 hrs" with "1 hr"
+	duration = strings.Replace(duration, "1 hrs", "1 hr", 1)
+
+	// Replace "1 mins" with "1 min"
+	duration = strings.Replace(duration, "1 mins", "1 min", 1)
+
+	// Replace "1 secs" with "1 sec"
+	duration = strings.Replace(duration, "1 secs", "1 sec", 1)
+
+	return duration
+}
+
+// ShortenLeaseDuration trims trailing zero units from a lease duration string.
+//
+// For example:
+// - "1 days, 0 hrs, 0 mins, 0 secs" becomes "1 day"
+// - "1 days, 0 hrs, 5 mins, 0 secs" becomes "1 day, 0 hrs, 5 mins"
+// - "1 days, 0 hrs, 0 mins, 1 secs" becomes "1 day, 0 hrs, 0 mins, 1 sec"
+//
+// The function preserves any non-zero units and removes only trailing zero units.
+// It also ensures correct singular/plural forms.
+func ShortenLeaseDuration(leaseDuration string) string {
+	// Split the duration into its components
+	components := strings.Split(leaseDuration, ", ")
+
+	// Find the last non-zero component
+	lastNonZeroIndex := len(components) - 1
+	for i := len(components) - 1; i > 0; i-- {
+		if !strings.HasPrefix(components[i], "0 ") {
+			break
+		}
+		lastNonZeroIndex = i - 1
+	}
+
+	// If all components after the first one are zero, return just the first component
+	if lastNonZeroIndex == 0 {
+		return FormatWithCorrectPlurals(components[0])
+	}
+
+	// Join the components up to and including the last non-zero one
+	shortened := strings.Join(components[:lastNonZeroIndex+1], ", ")
+
+	// Apply pluralization formatting
+	return FormatWithCorrectPlurals(shortened)
+}
+
+// ExpiresIn returns how long the lease is still valid at now, which is negative once it expired;
+// ok is false when the expiration time is missing or not in the YYYY-MM-DD HH:MM:SS format used on
+// Linux and Windows
+func (l DHCPLease) ExpiresIn(now time.Time) (time.Duration, bool) {
+	expires, err := time.ParseInLocation("2006-01-02 15:04:05", l.LeaseExpires, time.Local)
+	if err != nil {
+		return 0, false
+	}
+	return expires.Sub(now), true
+}
@@ -0,0 +1,231 @@
+/*
+lookup.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+*/
+
+package nicinfo
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrNoRoute is returned by Via when no route reaches the destination
+var ErrNoRoute = errors.New("no route to host")
+
+// Path describes how traffic to a destination would leave the host
+type Path struct {
+	Destination string `json:"destination" yaml:"destination"`
+	Interface   string `json:"interface" yaml:"interface"`
+	// Source is the address of Interface that would most likely be used, empty if it has none
+	Source string `json:"source" yaml:"source"`
+	// Gateway is empty when the destination is directly connected
+	Gateway string `json:"gateway" yaml:"gateway"`
+	Route   Route  `json:"route" yaml:"route"`
+}
+
+// Via performs a longest prefix match of destination against the routing table of snap,
+// without sending any packets. Ties are broken by the lowest metric. snap should be collected
+// without Options.Brief or Options.Interface so that the source address can be found.
+// Only Linux has a complete routing table; on other platforms the subnets of the interfaces
+// that are up are treated as directly connected routes. A destination with a zone, such as
+// fe80::1%eth0, is only matched against the routes of the interface the zone names.
+func (snap *Snapshot) Via(destination string) (*Path, error) {
+	dst, err := netip.ParseAddr(destination)
+	if err != nil {
+		return nil, err
+	}
+	dst = dst.Unmap()
+	zone := snap.zoneInterface(dst.Zone())
+	unzoned := dst.WithZone("")
+
+	allRoutes := append(localRoutes(snap.Interfaces), snap.Routes...)
+	if runtime.GOOS != "linux" {
+		allRoutes = append(connectedRoutes(snap.Interfaces), allRoutes...)
+	}
+
+	var best *Route
+	var bestPrefix netip.Prefix
+	for i, r := range allRoutes {
+		prefix, err := netip.ParsePrefix(r.Destination)
+		if err != nil || !prefix.Contains(unzoned) {
+			continue
+		}
+		if len(zone) > 0 && !strings.EqualFold(r.Interface, zone) {
+			continue
+		}
+		if len(r.Flags) > 0 && !slices.Contains(r.Flags, "up") && !slices.Contains(r.Flags, "reject") {
+			continue
+		}
+		if best == nil || prefix.Bits() > bestPrefix.Bits() ||
+			(prefix.Bits() == bestPrefix.Bits() && r.Metric < best.Metric) {
+			best = &allRoutes[i]
+			bestPrefix = prefix
+		}
+	}
+	if best == nil || slices.Contains(best.Flags, "reject") {
+		return nil, fmt.Errorf("%w: %s", ErrNoRoute, dst)
+	}
+
+	path := &Path{
+		Destination: dst.String(),
+		Interface:   best.Interface,
+		Gateway:     best.Gateway,
+		Route:       *best,
+	}
+	nextHop := unzoned
+	if len(best.Gateway) > 0 {
+		if gw, err := netip.ParseAddr(best.Gateway); err == nil {
+			nextHop = sourceHint(gw, unzoned)
+		}
+	}
+	if slices.Contains(best.Flags, "local") && bestPrefix.Bits() == dst.BitLen() {
+		path.Source = dst.String()
+		return path, nil
+	}
+	for _, nic := range snap.Interfaces {
+		if strings.EqualFold(nic.Name, best.Interface) {
+			path.Source = sourceAddress(nic, nextHop)
+			break
+		}
+	}
+	return path, nil
+}
+
+// zoneInterface returns the interface named by an IPv6 zone, which is either a name or an index
+func (snap *Snapshot) zoneInterface(zone string) string {
+	if index, err := strconv.Atoi(zone); err == nil {
+		for _, nic := range snap.Interfaces {
+			if nic.Index == index {
+				return nic.Name
+			}
+		}
+	}
+	return zone
+}
+
+// PrimaryAddress returns the interface of the preferred default route and the address that most
+// outgoing traffic uses as its source; ok is false when there is no default route
+func (snap *Snapshot) PrimaryAddress() (iface, addr string, ok bool) {
+	for _, r := range snap.DefaultRoutes() {
+		gw, err := netip.ParseAddr(r.Gateway)
+		if err != nil {
+			continue
+		}
+		for _, nic := range snap.Interfaces {
+			if strings.EqualFold(nic.Name, r.Interface) {
+				if source := sourceAddress(nic, sourceHint(gw, netip.IPv6Unspecified())); len(source) > 0 {
+					return nic.Name, source, true
+				}
+			}
+		}
+	}
+	return "", "", false
+}
+
+// sourceHint returns what the source address for dst is matched against: the gateway, unless
+// it is a link-local router, which needs a global source address for a global destination
+func sourceHint(gateway, dst netip.Addr) netip.Addr {
+	if gateway.Is6() && gateway.IsLinkLocalUnicast() && !dst.IsLinkLocalUnicast() {
+		return dst
+	}
+	return gateway.WithZone("")
+}
+
+// sourceAddress picks the address of nic that the kernel would most likely use to reach
+// nextHop: one on the same subnet, otherwise the first global address of the same family
+func sourceAddress(nic Interface, nextHop netip.Addr) string {
+	candidates := nic.IPv4
+	if nextHop.Is6() {
+		candidates = nic.IPv6
+	}
+
+	fallback := ""
+	for _, addr := range candidates {
+		prefix, err := netip.ParsePrefix(addr.String())
+		if err != nil {
+			continue
+		}
+		if prefix.Contains(nextHop) {
+			return addr.IP
+		}
+		if len(fallback) == 0 && !prefix.Addr().IsLinkLocalUnicast() {
+			fallback = addr.IP
+		}
+	}
+	if len(fallback) == 0 && len(candidates) > 0 {
+		fallback = candidates[0].IP
+	}
+	return fallback
+}
+
+// localRoutes returns the routes for the host's own addresses and loopback subnets, which
+// Linux keeps in its local table rather than in /proc/net/route
+func localRoutes(allInterfaces []Interface) []Route {
+	loopback := "lo"
+	for _, nic := range allInterfaces {
+		if slices.Contains(nic.Flags, "loopback") {
+			loopback = nic.Name
+			break
+		}
+	}
+
+	var allRoutes []Route
+	for _, nic := range allInterfaces {
+		for _, addr := range append(append([]Address{}, nic.IPv4...), nic.IPv6...) {
+			prefix, err := netip.ParsePrefix(addr.String())
+			if err != nil {
+				continue
+			}
+			if nic.Name == loopback {
+				allRoutes = append(allRoutes, Route{
+					Destination: prefix.Masked().String(),
+					Interface:   loopback,
+					Flags:       []string{"up", "local"},
+				})
+			}
+			allRoutes = append(allRoutes, Route{
+				Destination: netip.PrefixFrom(prefix.Addr(), prefix.Addr().BitLen()).String(),
+				Interface:   loopback,
+				Flags:       []string{"up", "local"},
+			})
+		}
+	}
+	return allRoutes
+}
+
+// connectedRoutes returns a route for the subnet of every address of the interfaces that are up
+func connectedRoutes(allInterfaces []Interface) []Route {
+	var allRoutes []Route
+	for _, nic := range allInterfaces {
+		if !slices.Contains(nic.Flags, "up") {
+			continue
+		}
+		for _, addr := range append(append([]Address{}, nic.IPv4...), nic.IPv6...) {
+			prefix, err := netip.ParsePrefix(addr.String())
+			if err != nil {
+				continue
+			}
+			allRoutes = append(allRoutes, Route{
+				Destination: prefix.Masked().String(),
+				Interface:   nic.Name,
+				Flags:       []string{"up"},
+			})
+		}
+	}
+	return allRoutes
+}
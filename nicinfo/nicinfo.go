@@ -0,0 +1,417 @@
+/*
+nicinfo.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+*/
+
+// Package nicinfo collects the network interfaces, DHCP leases, routes and DNS resolver
+// configuration of the local host. It is the library behind the nics command.
+package nicinfo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SchemaVersion is incremented whenever a field of Snapshot is renamed or removed;
+// adding a new field does not change it
+const SchemaVersion = 1
+
+// ErrInterfaceNotFound is returned by Collect when Options.Interface does not exist
+var ErrInterfaceNotFound = errors.New("interface not found")
+
+// Options selects what Collect gathers
+type Options struct {
+	// Brief only keeps interfaces that have a usable IPv4 address, see isBriefEntry
+	Brief bool
+	// Interface limits the snapshot to a single interface; matching is case-insensitive
+	// and overrides Brief
+	Interface string
+	// Debug, when not nil, receives a trace of how each interface was selected
+	Debug io.Writer
+}
+
+// Snapshot is everything collected in a single call to Collect; it is also what the
+// json, yaml and ndjson output modes of nics emit
+type Snapshot struct {
+	SchemaVersion int `json:"schema_version" yaml:"schema_version"`
+	// Brief and SelectedInterface are the Options.Brief and Options.Interface that selected
+	// Interfaces; Brief is false when an interface was selected
+	Brief             bool        `json:"brief" yaml:"brief"`
+	SelectedInterface string      `json:"selected_interface" yaml:"selected_interface"`
+	Interfaces        []Interface `json:"interfaces" yaml:"interfaces"`
+	DHCP              []DHCPLease `json:"dhcp" yaml:"dhcp"`
+	Routes            []Route     `json:"routes" yaml:"routes"`
+	Resolver          Resolver    `json:"resolver" yaml:"resolver"`
+	// Warnings holds errors that did not stop collection, such as a missing resolv.conf
+	Warnings []string `json:"warnings" yaml:"warnings"`
+}
+
+// Interface is a single network interface
+type Interface struct {
+	Name  string    `json:"name" yaml:"name"`
+	Index int       `json:"index" yaml:"index"`
+	MAC   string    `json:"mac" yaml:"mac"`
+	MTU   int       `json:"mtu" yaml:"mtu"`
+	Flags []string  `json:"flags" yaml:"flags"`
+	IPv4  []Address `json:"ipv4" yaml:"ipv4"`
+	IPv6  []Address `json:"ipv6" yaml:"ipv6"`
+	// Stats is nil on platforms where the counters are not available
+	Stats *InterfaceStats `json:"stats" yaml:"stats"`
+}
+
+// InterfaceStats are the traffic counters of an interface since it was brought up;
+// overruns are the FIFO errors of the device
+type InterfaceStats struct {
+	RxBytes    uint64 `json:"rx_bytes" yaml:"rx_bytes"`
+	RxPackets  uint64 `json:"rx_packets" yaml:"rx_packets"`
+	RxErrors   uint64 `json:"rx_errors" yaml:"rx_errors"`
+	RxDropped  uint64 `json:"rx_dropped" yaml:"rx_dropped"`
+	RxOverruns uint64 `json:"rx_overruns" yaml:"rx_overruns"`
+	Multicast  uint64 `json:"multicast" yaml:"multicast"`
+	TxBytes    uint64 `json:"tx_bytes" yaml:"tx_bytes"`
+	TxPackets  uint64 `json:"tx_packets" yaml:"tx_packets"`
+	TxErrors   uint64 `json:"tx_errors" yaml:"tx_errors"`
+	TxDropped  uint64 `json:"tx_dropped" yaml:"tx_dropped"`
+	TxOverruns uint64 `json:"tx_overruns" yaml:"tx_overruns"`
+}
+
+// Address is an IP address assigned to an interface
+type Address struct {
+	IP        string `json:"ip" yaml:"ip"`
+	PrefixLen int    `json:"prefix_len" yaml:"prefix_len"`
+}
+
+// DHCPLease is the lease an interface obtained from a DHCP server; the times are
+// formatted the way the platform reports them
+type DHCPLease struct {
+	Interface     string `json:"interface" yaml:"interface"`
+	IP            string `json:"ip" yaml:"ip"`
+	Server        string `json:"server" yaml:"server"`
+	LeaseStart    string `json:"lease_start" yaml:"lease_start"`
+	LeaseExpires  string `json:"lease_expires" yaml:"lease_expires"`
+	LeaseDuration string `json:"lease_duration" yaml:"lease_duration"`
+}
+
+// Route is a single entry of the routing table. The complete IPv4 and IPv6 tables are
+// collected on Linux; other platforms only report their default routes. Metric and
+// Flags are only known on Linux. Gateway is empty for directly connected routes.
+type Route struct {
+	Destination string   `json:"destination" yaml:"destination"`
+	Gateway     string   `json:"gateway" yaml:"gateway"`
+	Interface   string   `json:"interface" yaml:"interface"`
+	Metric      int64    `json:"metric" yaml:"metric"`
+	Flags       []string `json:"flags" yaml:"flags"`
+}
+
+// IsIPv6 reports whether r is part of the IPv6 routing table
+func (r Route) IsIPv6() bool {
+	return strings.Contains(r.Destination, ":")
+}
+
+// IsDefault reports whether r is a usable default route: its destination is 0.0.0.0/0 or ::/0,
+// it has a gateway and it is up
+func (r Route) IsDefault() bool {
+	if r.Destination != "0.0.0.0/0" && r.Destination != "::/0" {
+		return false
+	}
+	if len(r.Gateway) == 0 {
+		return false
+	}
+	return len(r.Flags) == 0 || slices.Contains(r.Flags, "up")
+}
+
+// Resolver is the DNS resolver configuration. Nameservers lists the addresses of Servers.
+// Domain, Sortlist and Options are only known on platforms that use /etc/resolv.conf;
+// Options is nil elsewhere. When /etc/resolv.conf points at a local stub, such as
+// systemd-resolved's 127.0.0.53, StubResolver is its address and Servers are the upstream
+// servers the stub forwards to.
+type Resolver struct {
+	Nameservers  []string    `json:"nameservers" yaml:"nameservers"`
+	Servers      []DNSServer `json:"servers" yaml:"servers"`
+	StubResolver string      `json:"stub_resolver" yaml:"stub_resolver"`
+	// LinkDomains are the search and routing-only (~ prefixed) domains of each interface
+	LinkDomains map[string][]string `json:"link_domains" yaml:"link_domains"`
+	Domain      string              `json:"domain" yaml:"domain"`
+	Search      []string            `json:"search" yaml:"search"`
+	Sortlist    []string            `json:"sortlist" yaml:"sortlist"`
+	Options     *ResolverOptions    `json:"options" yaml:"options"`
+}
+
+// where the address of a DNSServer was found
+const (
+	SourceResolvConf = "resolv.conf"
+	SourceResolved   = "resolved"
+	// SourceDHCP is a server that the current DHCP lease of an interface handed out, Linux only
+	SourceDHCP = "dhcp"
+	// SourceSystem is the configuration reported by scutil on macOS or GetNetworkParams on Windows
+	SourceSystem = "system"
+)
+
+// DNSServer is a single nameserver
+type DNSServer struct {
+	Address string `json:"address" yaml:"address"`
+	// Family is either ipv4 or ipv6
+	Family string `json:"family" yaml:"family"`
+	Source string `json:"source" yaml:"source"`
+	// Interface is set when the server is only used for queries over that interface
+	Interface string `json:"interface" yaml:"interface"`
+}
+
+// dnsServers converts a list of nameserver addresses that were all found in source
+func dnsServers(nameservers []string, source, iface string) []DNSServer {
+	allServers := []DNSServer{}
+	for _, ns := range nameservers {
+		family := "ipv4"
+		if strings.Contains(ns, ":") {
+			family = "ipv6"
+		}
+		allServers = append(allServers, DNSServer{Address: ns, Family: family, Source: source, Interface: iface})
+	}
+	return allServers
+}
+
+// Collect gathers a Snapshot of the local host. When opts.Interface does not exist, the
+// returned Snapshot is still populated with everything but interfaces, and the error
+// wraps ErrInterfaceNotFound.
+func Collect(ctx context.Context, opts Options) (*Snapshot, error) {
+	allInterfaces, found, err := collectInterfaces(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	allDhcpInfo, allRoutes, conf, warnings := collectNetworkConfig(ctx, allInterfaces)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	snap := &Snapshot{
+		SchemaVersion:     SchemaVersion,
+		Brief:             opts.Brief && len(opts.Interface) == 0,
+		SelectedInterface: opts.Interface,
+		Interfaces:        allInterfaces,
+		DHCP:              append([]DHCPLease{}, allDhcpInfo...),
+		Routes:            append([]Route{}, allRoutes...),
+		Resolver:          conf,
+		Warnings:          append([]string{}, warnings...),
+	}
+	for i := range snap.Routes {
+		snap.Routes[i].Flags = append([]string{}, snap.Routes[i].Flags...)
+	}
+	snap.Resolver.Nameservers = append([]string{}, conf.Nameservers...)
+	snap.Resolver.Servers = append([]DNSServer{}, conf.Servers...)
+	if snap.Resolver.LinkDomains == nil {
+		snap.Resolver.LinkDomains = make(map[string][]string)
+	}
+	snap.Resolver.Search = append([]string{}, conf.Search...)
+	snap.Resolver.Sortlist = append([]string{}, conf.Sortlist...)
+
+	if !found {
+		return snap, fmt.Errorf("%w: %s", ErrInterfaceNotFound, strings.ToLower(opts.Interface))
+	}
+	return snap, nil
+}
+
+// DefaultRoutes returns the usable default routes, IPv4 before IPv6, each ordered by metric
+// so that the route the kernel prefers is first
+func (snap *Snapshot) DefaultRoutes() []Route {
+	var defaults []Route
+	for _, r := range snap.Routes {
+		if r.IsDefault() {
+			defaults = append(defaults, r)
+		}
+	}
+	sort.SliceStable(defaults, func(i, j int) bool {
+		if defaults[i].IsIPv6() != defaults[j].IsIPv6() {
+			return !defaults[i].IsIPv6()
+		}
+		return defaults[i].Metric < defaults[j].Metric
+	})
+	return defaults
+}
+
+// String returns the address in CIDR notation
+func (a Address) String() string {
+	return a.IP + "/" + strconv.Itoa(a.PrefixLen)
+}
+
+// newAddress converts an interface address into an IP and prefix length;
+// addresses without a mask are given a host prefix
+func newAddress(netAddr net.Addr) Address {
+	switch a := netAddr.(type) {
+	case *net.IPNet:
+		ones, _ := a.Mask.Size()
+		return Address{IP: a.IP.String(), PrefixLen: ones}
+	case *net.IPAddr:
+		if a.IP.To4() != nil {
+			return Address{IP: a.IP.String(), PrefixLen: 32}
+		}
+		return Address{IP: a.IP.String(), PrefixLen: 128}
+	}
+	return Address{IP: netAddr.String()}
+}
+
+func addressStrings(allAddresses []Address) []string {
+	var result []string
+	for _, addr := range allAddresses {
+		result = append(result, addr.String())
+	}
+	return result
+}
+
+// interfaceForIP returns the name of the interface that owns ip, or an empty string
+func interfaceForIP(allInterfaces []Interface, ip string) string {
+	for _, nic := range allInterfaces {
+		for _, addr := range append(append([]Address{}, nic.IPv4...), nic.IPv6...) {
+			if addr.IP == ip {
+				return nic.Name
+			}
+		}
+	}
+	return ""
+}
+
+// isSelfAssigned reports whether ipv4 is an IPv4 link-local address, which hosts assign themselves
+// when no DHCP server answers
+func isSelfAssigned(ipv4 string) bool {
+	return strings.HasPrefix(ipv4, "169.254.")
+}
+
+func isBriefEntry(ifaceName, macAddr, mtu, flags string, ipv4List, ipv6List []string, debug io.Writer) bool {
+	if debug != nil {
+		fmt.Fprintln(debug, "isBriefEntry:", ifaceName)
+	}
+	if strings.Contains(flags, "loopback") {
+		if debug != nil {
+			fmt.Fprintln(debug, "   not_brief: loopback flag")
+		}
+		return false
+	}
+	if strings.HasPrefix(macAddr, "00:00:00:00:00:00") {
+		if debug != nil {
+			fmt.Fprintln(debug, "   not_brief: NULL macAddr")
+		}
+		return false
+	}
+	if 0 == len(ipv4List) {
+		if debug != nil {
+			fmt.Fprintln(debug, "   not_brief: no IP addresses")
+		}
+		return false
+	}
+	for _, ipv4 := range ipv4List {
+		if isSelfAssigned(ipv4) {
+			if debug != nil {
+				fmt.Fprintln(debug, "   not_brief: self assigned:", ipv4)
+			}
+			return false
+		}
+	}
+	if debug != nil {
+		fmt.Fprintln(debug, "    is_brief: true")
+	}
+	return true
+}
+
+func extractIPAddrs(ifaceName string, allAddresses []net.Addr, brief bool) ([]Address, []Address) {
+	allIPv4 := []Address{}
+	allIPv6 := []Address{}
+
+	for _, netAddr := range allAddresses {
+		addr := newAddress(netAddr)
+		if strings.Contains(addr.IP, ":") {
+			allIPv6 = append(allIPv6, addr)
+		} else {
+			allIPv4 = append(allIPv4, addr)
+		}
+	}
+	return allIPv4, allIPv6
+}
+
+// collectInterfaces returns the interfaces selected by opts; found is false when
+// opts.Interface was given but does not exist
+func collectInterfaces(ctx context.Context, opts Options) ([]Interface, bool, error) {
+	brief := opts.Brief
+	debug := opts.Debug
+	singleInterface := opts.Interface
+
+	adapters, err := net.Interfaces()
+	if err != nil {
+		return nil, false, err
+	}
+
+	foundSingleInterface := false
+	if len(singleInterface) > 0 {
+		brief = false
+		singleInterface = strings.ToLower(singleInterface)
+	}
+
+	allInterfaces := []Interface{}
+	for _, iface := range adapters {
+		if err := ctx.Err(); err != nil {
+			return nil, false, err
+		}
+		//fmt.Printf("%T %v\n", iface, iface)
+		allAddresses, err := iface.Addrs()
+		if err != nil {
+			return nil, false, err
+		}
+
+		allIPv4, allIPv6 := extractIPAddrs(iface.Name, allAddresses, brief)
+		if debug != nil {
+			fmt.Fprintln(debug)
+			fmt.Fprintln(debug, "---------------------")
+			fmt.Fprintln(debug, iface.Name, allAddresses)
+			fmt.Fprintln(debug, "ipv4:", allIPv4)
+			fmt.Fprintln(debug, "ipv6:", allIPv6)
+		}
+
+		ifaceName := strings.ToLower(iface.Name)
+		if len(singleInterface) > 0 && ifaceName != singleInterface {
+			continue
+		} else if len(singleInterface) > 0 && ifaceName == singleInterface {
+			foundSingleInterface = true
+		}
+		macAddr := iface.HardwareAddr.String()
+		mtu := strconv.Itoa(iface.MTU)
+		flags := iface.Flags.String()
+
+		if brief && !isBriefEntry(ifaceName, macAddr, mtu, flags, addressStrings(allIPv4), addressStrings(allIPv6), debug) {
+			continue
+		}
+
+		nic := Interface{
+			Name:  iface.Name,
+			Index: iface.Index,
+			MAC:   macAddr,
+			MTU:   iface.MTU,
+			Flags: []string{},
+			IPv4:  allIPv4,
+			IPv6:  allIPv6,
+		}
+		if iface.Flags != 0 {
+			nic.Flags = strings.Split(flags, "|")
+		}
+		if stats, err := ReadInterfaceStats(iface.Name); err == nil {
+			nic.Stats = stats
+		}
+		allInterfaces = append(allInterfaces, nic)
+	}
+	found := len(singleInterface) == 0 || foundSingleInterface
+	return allInterfaces, found, nil
+}
@@ -2,7 +2,7 @@
 // +build darwin
 
 /*
-nics_darwin.go
+nicinfo_darwin.go
 -John Taylor
 2019-08-03
 
@@ -15,13 +15,13 @@ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLI
 
 */
 
-package main
+package nicinfo
 
 import (
+	"context"
 	"fmt"
-	"github.com/olekukonko/tablewriter"
 	"golang.org/x/net/route"
-	"os"
+	"net"
 	"os/exec"
 	"regexp"
 	"strconv"
@@ -76,29 +76,6 @@ func ParseDHCPInfo(input string) (map[string]string, error) {
 	return result, nil
 }
 
-// FormatLeaseTime converts lease time in seconds to a human-readable format
-// showing days, hours, minutes, and seconds.
-func FormatLeaseTime(secondsStr string) (string, error) {
-	// Convert string to integer
-	seconds, err := strconv.ParseInt(secondsStr, 10, 64)
-	if err != nil {
-		return "", fmt.Errorf("failed to parse lease time: %v", err)
-	}
-
-	// Calculate days, hours, minutes, seconds
-	days := seconds / (24 * 60 * 60)
-	seconds %= 24 * 60 * 60
-
-	hours := seconds / (60 * 60)
-	seconds %= 60 * 60
-
-	minutes := seconds / 60
-	seconds %= 60
-
-	// Format the result
-	return fmt.Sprintf("%d days, %d hrs, %d mins, %d secs", days, hours, minutes, seconds), nil
-}
-
 // adopted from: https://stackoverflow.com/a/31221013/452281
 func convert(b []byte) string {
 	s := make([]string, len(b))
@@ -108,51 +85,44 @@ func convert(b []byte) string {
 	return strings.Join(s, ".")
 }
 
-// extract the nameservers from this cmd-line output: scutil --dns
-func parseScutilOutput(output string) []string {
-	var dns = []string{"N/A", "N/A"}
+// extract the nameservers and search domains of the first resolver from this cmd-line output: scutil --dns
+func parseScutilOutput(output string) ([]string, []string) {
+	var nameservers, search []string
 	allLines := strings.Split(output, "\n")
-	i := 0
 	resolverCount := 0
 	for _, line := range allLines {
-		//fmt.Println("rc:", resolverCount, "   i:", i, "   dns:", dns, "  line:", line)
 		if strings.Contains(line, "resolver #") {
 			resolverCount += 1
-			if resolverCount >= 2 && i > 0 {
+			if resolverCount >= 2 && len(nameservers) > 0 {
 				break
 			}
 		}
-		if strings.Contains(line, "nameserver[") {
-			clean := strings.TrimSpace(line)
-			slots := strings.Split(clean, " ")
-			if len(slots) == 3 {
-				dns[i] = slots[2]
-				i += 1
-				if i == 2 {
-					break
-				}
-			}
+		key, value, found := strings.Cut(strings.TrimSpace(line), " : ")
+		if !found {
+			continue
+		}
+		if strings.HasPrefix(key, "nameserver[") {
+			nameservers = append(nameservers, strings.TrimSpace(value))
+		} else if strings.HasPrefix(key, "search domain[") {
+			search = append(search, strings.TrimSpace(value))
 		}
 	}
-	return dns
+	return nameservers, search
 }
 
-func getMacOSDNS() []string {
-	var dns = []string{"N/A", "N/A"}
-
-	dnsCmd := exec.Command("/usr/sbin/scutil", "--dns")
+func getMacOSDNS(ctx context.Context) ([]string, []string) {
+	dnsCmd := exec.CommandContext(ctx, "/usr/sbin/scutil", "--dns")
 	output, err := dnsCmd.CombinedOutput()
 	if err != nil {
-		return dns
+		return nil, nil
 	}
-	dns = parseScutilOutput(string(output))
-	return dns
+	return parseScutilOutput(string(output))
 }
 
-func getMacOSDhcp(allAdapters []string) {
-	var allDhcpInfo []map[string]string
+func getMacOSDhcp(ctx context.Context, allAdapters []string) []DHCPLease {
+	var allDhcpInfo []DHCPLease
 	for _, adapter := range allAdapters {
-		dhcpCmd := exec.Command("/usr/sbin/ipconfig", "getsummary", adapter)
+		dhcpCmd := exec.CommandContext(ctx, "/usr/sbin/ipconfig", "getsummary", adapter)
 		output, err := dhcpCmd.CombinedOutput()
 		if err != nil {
 			continue
@@ -161,31 +131,26 @@ func getMacOSDhcp(allAdapters []string) {
 		if err != nil {
 			continue
 		}
-		dhcpInfo["adapter"] = adapter
-		allDhcpInfo = append(allDhcpInfo, dhcpInfo)
+		allDhcpInfo = append(allDhcpInfo, DHCPLease{
+			Interface:     adapter,
+			Server:        dhcpInfo["server_identifier"],
+			LeaseStart:    dhcpInfo["LeaseStartTime"],
+			LeaseExpires:  dhcpInfo["LeaseExpirationTime"],
+			LeaseDuration: ShortenLeaseDuration(dhcpInfo["formatted_lease_time"]),
+		})
 	}
-	renderDHCPTable(allDhcpInfo)
-}
-
-func renderDHCPTable(allDhcpInfo []map[string]string) {
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetAutoWrapText(false)
-	table.SetHeader([]string{"Name", "DHCP Server", "Lease Start", "Lease Expiration", "Lease Duration"})
-	for _, adapter := range allDhcpInfo {
-		shortLeaseDur := ShortenLeaseDuration(adapter["formatted_lease_time"])
-		table.Append([]string{adapter["adapter"], adapter["server_identifier"], adapter["LeaseStartTime"], adapter["LeaseExpirationTime"], shortLeaseDur})
-	}
-	table.Render()
+	return allDhcpInfo
 }
 
 // adopted from: https://gist.github.com/abimaelmartell/dcbbff464dc0778165b2dcc5092f90e6
-func getMacOSDefaultGateway() string {
+// the name of the interface the gateway is reached through is also returned, when known
+func getMacOSDefaultGateway() (string, string) {
 	var defaultRoute = [4]byte{0, 0, 0, 0}
 	rib, _ := route.FetchRIB(0, route.RIBTypeRoute, 0)
 	messages, err := route.ParseRIB(route.RIBTypeRoute, rib)
 
 	if err != nil {
-		return "N/A"
+		return "N/A", ""
 	}
 
 	for _, message := range messages {
@@ -208,21 +173,31 @@ func getMacOSDefaultGateway() string {
 		}
 
 		if destination.IP == defaultRoute {
-			return convert(gateway.IP[:])
+			ifaceName := ""
+			if iface, err := net.InterfaceByIndex(route_message.Index); err == nil {
+				ifaceName = iface.Name
+			}
+			return convert(gateway.IP[:]), ifaceName
 		}
 	}
-	return "N/A"
+	return "N/A", ""
 }
 
-func gatewayAndDNS(allIPv4, allIPv6, allRenderedInterfaces []string, brief bool) {
-	getMacOSDhcp(allRenderedInterfaces)
+// collectNetworkConfig returns the DHCP leases, default routes and DNS resolver configuration
+func collectNetworkConfig(ctx context.Context, allInterfaces []Interface) ([]DHCPLease, []Route, Resolver, []string) {
+	var allRenderedInterfaces []string
+	for _, nic := range allInterfaces {
+		allRenderedInterfaces = append(allRenderedInterfaces, nic.Name)
+	}
+	allDhcpInfo := getMacOSDhcp(ctx, allRenderedInterfaces)
 
-	gateway := getMacOSDefaultGateway()
-	dns := getMacOSDNS()
+	var allRoutes []Route
+	if gateway, ifaceName := getMacOSDefaultGateway(); gateway != "N/A" {
+		allRoutes = append(allRoutes, Route{Destination: "0.0.0.0/0", Gateway: gateway, Interface: ifaceName})
+	}
 
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetAutoWrapText(false)
-	table.SetHeader([]string{"Gateway", "DNS 1", "DNS 2"})
-	table.Append([]string{gateway, dns[0], dns[1]})
-	table.Render()
+	var conf Resolver
+	conf.Nameservers, conf.Search = getMacOSDNS(ctx)
+	conf.Servers = dnsServers(conf.Nameservers, SourceSystem, "")
+	return allDhcpInfo, allRoutes, conf, nil
 }
@@ -0,0 +1,31 @@
+//go:build freebsd
+// +build freebsd
+
+/*
+nics_darwin.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+*/
+
+package nicinfo
+
+import "context"
+
+// collectNetworkConfig returns the DNS resolver configuration;
+// DHCP leases and routes are not yet implemented on FreeBSD
+func collectNetworkConfig(ctx context.Context, allInterfaces []Interface) ([]DHCPLease, []Route, Resolver, []string) {
+	var warnings []string
+	conf, err := readResolvConf(resolvConfFile)
+	if err != nil {
+		warnings = append(warnings, err.Error())
+	}
+	return nil, nil, conf, warnings
+}
@@ -0,0 +1,318 @@
+//go:build linux
+// +build linux
+
+/*
+nicinfo_linux.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+*/
+
+package nicinfo
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// adapted from: https://stackoverflow.com/a/40695315/452281
+
+/* /proc/net/route file:
+Iface   Destination Gateway     Flags   RefCnt  Use Metric  Mask
+eno1    00000000    C900A8C0    0003    0   0   100 00000000    0   00
+eno1    0000A8C0    00000000    0001    0   0   100 00FFFFFF    0   00
+*/
+
+const procNetRoute = "/proc/net/route"
+
+// route flags, from linux/route.h and linux/ipv6_route.h
+const (
+	rtfUp      = 0x0001
+	rtfGateway = 0x0002
+)
+
+var routeFlagNames = []struct {
+	flag uint64
+	name string
+}{
+	{rtfUp, "up"},
+	{rtfGateway, "gateway"},
+	{0x0004, "host"},
+	{0x0008, "reinstate"},
+	{0x0010, "dynamic"},
+	{0x0020, "modified"},
+	{0x0040, "mtu"},
+	{0x0080, "window"},
+	{0x0100, "irtt"},
+	{0x0200, "reject"},
+	{0x00010000, "default"},
+	{0x00020000, "allonlink"},
+	{0x00040000, "addrconf"},
+	{0x00080000, "prefix"},
+	{0x00100000, "anycast"},
+	{0x00200000, "nonexthop"},
+	{0x00400000, "expires"},
+	{0x00800000, "routeinfo"},
+	{0x01000000, "cache"},
+	{0x02000000, "flow"},
+	{0x04000000, "policy"},
+	{0x40000000, "pcpu"},
+	{0x80000000, "local"},
+}
+
+// decodeRouteFlags converts the RTF_* bits of a route into words, such as: up, gateway, host
+func decodeRouteFlags(flags uint64) []string {
+	words := []string{}
+	for _, f := range routeFlagNames {
+		if flags&f.flag != 0 {
+			words = append(words, f.name)
+		}
+	}
+	return words
+}
+
+// ipv4Route is a single line of /proc/net/route
+type ipv4Route struct {
+	iface       string
+	destination net.IP
+	gateway     net.IP
+	mask        net.IPMask
+	flags       uint64
+	metric      int64
+}
+
+// hexToIPv4 converts an address from /proc/net/route, which is in host (little endian) byte order
+func hexToIPv4(hex string) (net.IP, error) {
+	d, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return nil, err
+	}
+	ip := make(net.IP, 4)
+	binary.LittleEndian.PutUint32(ip, uint32(d))
+	return ip, nil
+}
+
+// parseIPv4Routes returns every entry of a /proc/net/route formatted file; malformed lines are skipped
+func parseIPv4Routes(f io.Reader) ([]ipv4Route, error) {
+	var allRoutes []ipv4Route
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 8 || fields[0] == "Iface" {
+			continue
+		}
+
+		destination, err := hexToIPv4(fields[1])
+		if err != nil {
+			continue
+		}
+		gateway, err := hexToIPv4(fields[2])
+		if err != nil {
+			continue
+		}
+		flags, err := strconv.ParseUint(fields[3], 16, 32)
+		if err != nil {
+			continue
+		}
+		metric, err := strconv.ParseInt(fields[6], 10, 64)
+		if err != nil {
+			continue
+		}
+		mask, err := hexToIPv4(fields[7])
+		if err != nil {
+			continue
+		}
+
+		allRoutes = append(allRoutes, ipv4Route{
+			iface:       fields[0],
+			destination: destination,
+			gateway:     gateway,
+			mask:        net.IPMask(mask),
+			flags:       flags,
+			metric:      metric,
+		})
+	}
+	return allRoutes, scanner.Err()
+}
+
+func readIPv4Routes() ([]ipv4Route, error) {
+	f, err := os.Open(procNetRoute)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseIPv4Routes(f)
+}
+
+// ipv4RouteTable converts the entries of /proc/net/route, keeping the kernel's order
+func ipv4RouteTable(allRoutes []ipv4Route) []Route {
+	var table []Route
+	for _, r := range allRoutes {
+		ones, _ := r.mask.Size()
+		entry := Route{
+			Destination: fmt.Sprintf("%s/%d", r.destination, ones),
+			Interface:   r.iface,
+			Metric:      r.metric,
+			Flags:       decodeRouteFlags(r.flags),
+		}
+		if r.flags&rtfGateway != 0 {
+			entry.Gateway = r.gateway.String()
+		}
+		table = append(table, entry)
+	}
+	return table
+}
+
+/* /proc/net/ipv6_route file:
+destination                      len source                           len next hop                         metric   refcnt   use      flags        iface
+00000000000000000000000000000000 00 00000000000000000000000000000000 00 fe800000000000000000000000000001 00000400 00000001 00000000 00000003     eth0
+*/
+
+const procNetIPv6Route = "/proc/net/ipv6_route"
+
+// ipv6Route is a single line of /proc/net/ipv6_route
+type ipv6Route struct {
+	iface       string
+	destination net.IP
+	prefixLen   int
+	nextHop     net.IP
+	flags       uint64
+	metric      int64
+}
+
+// hexToIPv6 converts an address from /proc/net/ipv6_route, which is in network byte order
+func hexToIPv6(hexAddr string) (net.IP, error) {
+	b, err := hex.DecodeString(hexAddr)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != net.IPv6len {
+		return nil, fmt.Errorf("invalid IPv6 address: %s", hexAddr)
+	}
+	return net.IP(b), nil
+}
+
+// parseIPv6Routes returns every entry of a /proc/net/ipv6_route formatted file; malformed lines are skipped
+func parseIPv6Routes(f io.Reader) ([]ipv6Route, error) {
+	var allRoutes []ipv6Route
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 {
+			continue
+		}
+
+		destination, err := hexToIPv6(fields[0])
+		if err != nil {
+			continue
+		}
+		prefixLen, err := strconv.ParseUint(fields[1], 16, 8)
+		if err != nil {
+			continue
+		}
+		nextHop, err := hexToIPv6(fields[4])
+		if err != nil {
+			continue
+		}
+		metric, err := strconv.ParseUint(fields[5], 16, 32)
+		if err != nil {
+			continue
+		}
+		flags, err := strconv.ParseUint(fields[8], 16, 32)
+		if err != nil {
+			continue
+		}
+
+		allRoutes = append(allRoutes, ipv6Route{
+			iface:       fields[9],
+			destination: destination,
+			prefixLen:   int(prefixLen),
+			nextHop:     nextHop,
+			flags:       flags,
+			metric:      int64(metric),
+		})
+	}
+	return allRoutes, scanner.Err()
+}
+
+func readIPv6Routes() ([]ipv6Route, error) {
+	f, err := os.Open(procNetIPv6Route)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseIPv6Routes(f)
+}
+
+// ipv6NextHop formats a gateway address; link-local addresses are only meaningful together
+// with the interface they are reached through, so the zone is appended to them
+func ipv6NextHop(nextHop net.IP, iface string) string {
+	if nextHop.IsLinkLocalUnicast() {
+		return nextHop.String() + "%" + iface
+	}
+	return nextHop.String()
+}
+
+// ipv6RouteTable converts the entries of /proc/net/ipv6_route, keeping the kernel's order
+func ipv6RouteTable(allRoutes []ipv6Route) []Route {
+	var table []Route
+	for _, r := range allRoutes {
+		entry := Route{
+			Destination: fmt.Sprintf("%s/%d", r.destination, r.prefixLen),
+			Interface:   r.iface,
+			Metric:      r.metric,
+			Flags:       decodeRouteFlags(r.flags),
+		}
+		if r.flags&rtfGateway != 0 {
+			entry.Gateway = ipv6NextHop(r.nextHop, r.iface)
+		}
+		table = append(table, entry)
+	}
+	return table
+}
+
+// collectNetworkConfig returns the DHCP leases, the IPv4 and IPv6 routing tables and DNS resolver configuration
+func collectNetworkConfig(ctx context.Context, allInterfaces []Interface) ([]DHCPLease, []Route, Resolver, []string) {
+	var warnings []string
+	conf, err := readResolvConf(resolvConfFile)
+	if err != nil {
+		warnings = append(warnings, err.Error())
+	} else if err := applyResolved(&conf); err != nil {
+		warnings = append(warnings, err.Error())
+	}
+
+	var allRoutes []Route
+	ipv4Routes, err := readIPv4Routes()
+	if err != nil {
+		warnings = append(warnings, err.Error())
+	} else {
+		allRoutes = ipv4RouteTable(ipv4Routes)
+	}
+
+	// IPv6 may be disabled, in which case the file does not exist
+	ipv6Routes, err := readIPv6Routes()
+	if err == nil {
+		allRoutes = append(allRoutes, ipv6RouteTable(ipv6Routes)...)
+	} else if !os.IsNotExist(err) {
+		warnings = append(warnings, err.Error())
+	}
+
+	allLeases := currentLeases(allInterfaces)
+	applyDHCPServers(&conf, allLeases)
+	return collectDHCPLeases(allLeases), allRoutes, conf, warnings
+}
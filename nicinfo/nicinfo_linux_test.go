@@ -0,0 +1,235 @@
+//go:build linux
+// +build linux
+
+/*
+nicinfo_linux_test.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*/
+
+package nicinfo
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// readRouteTable parses a /proc/net/route or /proc/net/ipv6_route formatted file
+func readRouteTable(t *testing.T, fileName string) []Route {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", "routes", fileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if fileName == "ipv6_route" {
+		allRoutes, err := parseIPv6Routes(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ipv6RouteTable(allRoutes)
+	}
+	allRoutes, err := parseIPv4Routes(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ipv4RouteTable(allRoutes)
+}
+
+func TestRouteTables(t *testing.T) {
+	tests := []struct {
+		file     string
+		routes   []Route
+		defaults []Route
+	}{
+		{
+			// the VPN and docker routes are listed before the defaults, eth1's default is down,
+			// the truncated last line is skipped
+			file: "route",
+			routes: []Route{
+				{Destination: "0.0.0.0/1", Gateway: "10.8.0.5", Interface: "tun0", Metric: 0, Flags: []string{"up", "gateway"}},
+				{Destination: "128.0.0.0/1", Gateway: "10.8.0.5", Interface: "tun0", Metric: 0, Flags: []string{"up", "gateway"}},
+				{Destination: "172.17.0.0/16", Interface: "docker0", Metric: 0, Flags: []string{"up"}},
+				{Destination: "0.0.0.0/0", Gateway: "192.168.0.1", Interface: "eth0", Metric: 600, Flags: []string{"up", "gateway"}},
+				{Destination: "0.0.0.0/0", Gateway: "192.168.1.1", Interface: "wlan0", Metric: 100, Flags: []string{"up", "gateway"}},
+				{Destination: "0.0.0.0/0", Gateway: "10.10.0.1", Interface: "eth1", Metric: 50, Flags: []string{"gateway"}},
+				{Destination: "192.168.0.0/24", Interface: "eth0", Metric: 600, Flags: []string{"up"}},
+				{Destination: "10.0.0.0/8", Interface: "lo", Metric: 0, Flags: []string{"up", "reject"}},
+			},
+			defaults: []Route{
+				{Destination: "0.0.0.0/0", Gateway: "192.168.1.1", Interface: "wlan0", Metric: 100, Flags: []string{"up", "gateway"}},
+				{Destination: "0.0.0.0/0", Gateway: "192.168.0.1", Interface: "eth0", Metric: 600, Flags: []string{"up", "gateway"}},
+			},
+		},
+		{
+			// eth0's default has a link-local next hop, the line with an invalid destination is skipped
+			file: "ipv6_route",
+			routes: []Route{
+				{Destination: "::/0", Gateway: "fe80::1%eth0", Interface: "eth0", Metric: 1024, Flags: []string{"up", "gateway", "default", "addrconf", "expires"}},
+				{Destination: "2001:db8::/64", Interface: "eth0", Metric: 256, Flags: []string{"up"}},
+				{Destination: "::/0", Gateway: "2001:db8::1", Interface: "wg0", Metric: 100, Flags: []string{"up", "gateway"}},
+				{Destination: "fe80::/64", Interface: "eth0", Metric: 256, Flags: []string{"up"}},
+				{Destination: "::1/128", Interface: "lo", Metric: 0, Flags: []string{"up", "nonexthop", "local"}},
+			},
+			defaults: []Route{
+				{Destination: "::/0", Gateway: "2001:db8::1", Interface: "wg0", Metric: 100, Flags: []string{"up", "gateway"}},
+				{Destination: "::/0", Gateway: "fe80::1%eth0", Interface: "eth0", Metric: 1024, Flags: []string{"up", "gateway", "default", "addrconf", "expires"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			routes := readRouteTable(t, tt.file)
+			if !reflect.DeepEqual(routes, tt.routes) {
+				t.Errorf("routes:\n got %+v\nwant %+v", routes, tt.routes)
+			}
+			snap := Snapshot{Routes: routes}
+			if defaults := snap.DefaultRoutes(); !reflect.DeepEqual(defaults, tt.defaults) {
+				t.Errorf("default routes:\n got %+v\nwant %+v", defaults, tt.defaults)
+			}
+		})
+	}
+}
+
+func TestDecodeRouteFlags(t *testing.T) {
+	tests := []struct {
+		flags uint64
+		words []string
+	}{
+		{0, []string{}},
+		{0x0001, []string{"up"}},
+		{0x0003, []string{"up", "gateway"}},
+		{0x0007, []string{"up", "gateway", "host"}},
+		{0x0201, []string{"up", "reject"}},
+		{0x00450003, []string{"up", "gateway", "default", "addrconf", "expires"}},
+		{0x80200001, []string{"up", "nonexthop", "local"}},
+		// bits without a name are ignored
+		{0x20000001, []string{"up"}},
+	}
+
+	for _, tt := range tests {
+		if words := decodeRouteFlags(tt.flags); !reflect.DeepEqual(words, tt.words) {
+			t.Errorf("decodeRouteFlags(%#x) = %q, want %q", tt.flags, words, tt.words)
+		}
+	}
+}
+
+func TestIPv6NextHop(t *testing.T) {
+	tests := []struct {
+		nextHop string
+		iface   string
+		want    string
+	}{
+		{"fe80::1", "eth0", "fe80::1%eth0"},
+		{"2001:db8::1", "eth0", "2001:db8::1"},
+	}
+
+	for _, tt := range tests {
+		if got := ipv6NextHop(net.ParseIP(tt.nextHop), tt.iface); got != tt.want {
+			t.Errorf("ipv6NextHop(%s, %s) = %s, want %s", tt.nextHop, tt.iface, got, tt.want)
+		}
+	}
+}
+
+func TestVia(t *testing.T) {
+	up := []string{"up"}
+	gateway := []string{"up", "gateway"}
+	snap := &Snapshot{
+		Interfaces: []Interface{
+			{Name: "lo", Index: 1, Flags: []string{"up", "loopback", "running"},
+				IPv4: []Address{{"127.0.0.1", 8}}, IPv6: []Address{{"::1", 128}}},
+			{Name: "eth0", Index: 2, Flags: []string{"up", "running"},
+				IPv4: []Address{{"192.0.2.2", 24}}, IPv6: []Address{{"fe80::2", 64}, {"2001:db8::2", 64}}},
+			{Name: "wlan0", Index: 3, Flags: []string{"up", "running"},
+				IPv4: []Address{{"198.51.100.7", 24}}, IPv6: []Address{{"fe80::7", 64}}},
+			{Name: "tun0", Index: 4, Flags: []string{"up", "running"},
+				IPv4: []Address{{"10.8.0.6", 32}}},
+		},
+		Routes: []Route{
+			{Destination: "10.8.0.0/16", Gateway: "10.8.0.5", Interface: "tun0", Flags: gateway},
+			{Destination: "10.8.1.0/24", Interface: "lo", Flags: []string{"up", "reject"}},
+			{Destination: "10.9.0.0/16", Gateway: "192.0.2.9", Interface: "eth0", Flags: []string{"gateway"}},
+			{Destination: "172.16.0.0/12", Gateway: "192.0.2.1", Interface: "eth0", Metric: 50, Flags: gateway},
+			{Destination: "172.16.0.0/12", Gateway: "198.51.100.1", Interface: "wlan0", Metric: 10, Flags: gateway},
+			{Destination: "0.0.0.0/0", Gateway: "192.0.2.1", Interface: "eth0", Metric: 600, Flags: gateway},
+			{Destination: "0.0.0.0/0", Gateway: "198.51.100.1", Interface: "wlan0", Metric: 100, Flags: gateway},
+			{Destination: "192.0.2.0/24", Interface: "eth0", Metric: 600, Flags: up},
+			{Destination: "198.51.100.0/24", Interface: "wlan0", Metric: 100, Flags: up},
+			{Destination: "::/0", Gateway: "fe80::1%eth0", Interface: "eth0", Metric: 1024, Flags: gateway},
+			{Destination: "2001:db8::/64", Interface: "eth0", Metric: 256, Flags: up},
+			{Destination: "fe80::/64", Interface: "eth0", Metric: 256, Flags: up},
+			{Destination: "fe80::/64", Interface: "wlan0", Metric: 256, Flags: up},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		destination string
+		iface       string
+		source      string
+		gateway     string
+		route       string
+		err         error
+	}{
+		{"default with the lowest metric", "8.8.8.8", "wlan0", "198.51.100.7", "198.51.100.1", "0.0.0.0/0", nil},
+		{"directly connected", "192.0.2.50", "eth0", "192.0.2.2", "", "192.0.2.0/24", nil},
+		{"longest prefix", "10.8.3.4", "tun0", "10.8.0.6", "10.8.0.5", "10.8.0.0/16", nil},
+		{"reject route", "10.8.1.20", "", "", "", "", ErrNoRoute},
+		{"down route skipped", "10.9.1.1", "wlan0", "198.51.100.7", "198.51.100.1", "0.0.0.0/0", nil},
+		{"same prefix, lowest metric", "172.16.5.5", "wlan0", "198.51.100.7", "198.51.100.1", "172.16.0.0/12", nil},
+		{"own address", "192.0.2.2", "lo", "192.0.2.2", "", "192.0.2.2/32", nil},
+		{"loopback subnet", "127.0.0.5", "lo", "127.0.0.1", "", "127.0.0.0/8", nil},
+		{"IPv4-mapped", "::ffff:192.0.2.50", "eth0", "192.0.2.2", "", "192.0.2.0/24", nil},
+		{"link-local router, global source", "2606:4700::1", "eth0", "2001:db8::2", "fe80::1%eth0", "::/0", nil},
+		{"IPv6 directly connected", "2001:db8::99", "eth0", "2001:db8::2", "", "2001:db8::/64", nil},
+		{"zone name", "fe80::99%wlan0", "wlan0", "fe80::7", "", "fe80::/64", nil},
+		{"zone index", "fe80::99%3", "wlan0", "fe80::7", "", "fe80::/64", nil},
+		{"zone name in another case", "fe80::99%WLAN0", "wlan0", "fe80::7", "", "fe80::/64", nil},
+		{"no zone, first of equal routes", "fe80::99", "eth0", "fe80::2", "", "fe80::/64", nil},
+		{"zone without a route", "fe80::99%tun0", "", "", "", "", ErrNoRoute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := snap.Via(tt.destination)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("got %+v, %v; want %v", path, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if path.Interface != tt.iface || path.Source != tt.source || path.Gateway != tt.gateway || path.Route.Destination != tt.route {
+				t.Errorf("got %s via %s from %s over %s, want %s via %s from %s over %s",
+					path.Interface, path.Gateway, path.Source, path.Route.Destination, tt.iface, tt.gateway, tt.source, tt.route)
+			}
+		})
+	}
+}
+
+func TestPrimaryAddress(t *testing.T) {
+	// an IPv6-only host behind a link-local router uses its global address
+	snap := &Snapshot{
+		Interfaces: []Interface{{Name: "eth0", IPv6: []Address{{"fe80::2", 64}, {"2001:db8::2", 64}}}},
+		Routes:     []Route{{Destination: "::/0", Gateway: "fe80::1%eth0", Interface: "eth0", Flags: []string{"up", "gateway"}}},
+	}
+	iface, addr, ok := snap.PrimaryAddress()
+	if !ok || iface != "eth0" || addr != "2001:db8::2" {
+		t.Errorf("got %s %s %v, want eth0 2001:db8::2 true", iface, addr, ok)
+	}
+}
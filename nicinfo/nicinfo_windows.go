@@ -2,7 +2,7 @@
 // +build windows
 
 /*
-nics_windows.go
+nicinfo_windows.go
 -John Taylor
 2019-08-03
 
@@ -15,15 +15,13 @@ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLI
 
 */
 
-package main
+package nicinfo
 
 import (
+	"context"
 	"fmt"
-	"os"
 	"syscall"
 	"unsafe"
-
-	"github.com/olekukonko/tablewriter"
 )
 
 const (
@@ -100,21 +98,18 @@ func getDNSEntries() ([]string, error) {
 		return nil, err
 	}
 
-	var dns1, dns2 string
-	dns1 = sliceToString(netInfo[0].dnsServerList.address[:])
-
-	nextDNS := netInfo[0].dnsServerList.next
-	if nextDNS != nil {
-		dns2 = sliceToString(nextDNS.address[:])
+	var dns []string
+	for entry := &netInfo[0].dnsServerList; entry != nil; entry = entry.next {
+		dns = append(dns, sliceToString(entry.address[:]))
 	}
 
-	return []string{dns1, dns2}, nil
+	return dns, nil
 }
 
-func getGatewaysAndDHCP(brief bool) (map[string]string, error) {
+func getGatewaysAndDHCP(allInterfaces []Interface) ([]Route, []DHCPLease, error) {
 	err := getAdaptersInfo.Find()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	adapters := [16]ipAdapterInfo{}
@@ -122,11 +117,11 @@ func getGatewaysAndDHCP(brief bool) (map[string]string, error) {
 
 	result, _, err := getAdaptersInfo.Call(uintptr(unsafe.Pointer(&adapters[0])), uintptr(unsafe.Pointer(&size)))
 	if result != 0 {
-		return nil, err
+		return nil, nil, err
 	}
 
-	ipMapDHCP := make(map[string][]string) // key:adapter IP; values:0 = dhcp server ip, 1 = leaseObtained, 2 = leaseExpires
-	ipMapGateway := make(map[string]string)
+	var allRoutes []Route
+	var allDhcpInfo []DHCPLease
 
 	adapter := &adapters[0]
 	for adapter != nil {
@@ -143,62 +138,50 @@ func getGatewaysAndDHCP(brief bool) (map[string]string, error) {
 		leaseObtained := adapter.leaseObtained
 		leaseExpires := adapter.leaseExpires
 
-		if ip != "0.0.0.0" && gate != "0.0.0.0" {
-			ipMapGateway[ip] = gate
+		// only report gateways of the interfaces that are being rendered
+		ifaceName := interfaceForIP(allInterfaces, ip)
+		if ip != "0.0.0.0" && gate != "0.0.0.0" && len(ifaceName) > 0 {
+			allRoutes = append(allRoutes, Route{Destination: "0.0.0.0/0", Gateway: gate, Interface: ifaceName})
 		}
 		if len(dhcpServer) >= 4 {
-			ipMapDHCP[ip] = []string{dhcpServer, timeToString(leaseObtained), timeToString(leaseExpires)}
+			if len(ifaceName) == 0 {
+				ifaceName = ip
+			}
+			leaseDuration, _ := FormatLeaseTime(fmt.Sprintf("%d", leaseExpires-leaseObtained))
+			allDhcpInfo = append(allDhcpInfo, DHCPLease{
+				Interface:     ifaceName,
+				IP:            ip,
+				Server:        dhcpServer,
+				LeaseStart:    timeToString(leaseObtained),
+				LeaseExpires:  timeToString(leaseExpires),
+				LeaseDuration: ShortenLeaseDuration(leaseDuration),
+			})
 		}
 
 		adapter = adapter.next
 	}
 
-	if !brief && len(ipMapDHCP) > 0 {
-		renderDHCPTable(ipMapDHCP)
-	}
-
-	return ipMapGateway, nil
-}
-
-func renderDHCPTable(ipMapDHCP map[string][]string) {
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetAutoWrapText(false)
-	table.SetHeader([]string{"IP", "DHCP Server", "Lease Renewed", "Lease Expires"})
-	for ip, dhcpInfo := range ipMapDHCP {
-		table.Append([]string{ip, dhcpInfo[0], dhcpInfo[1], dhcpInfo[2]})
-	}
-	table.Render()
+	return allRoutes, allDhcpInfo, nil
 }
 
-func gatewayAndDNS(allIPv4, allIPv6, allRenderedInterfaces []string, brief bool) {
-	var err error
-	var dns = []string{"N/A", "N/A"}
-	dns, err = getDNSEntries()
+// collectNetworkConfig returns the DHCP leases, default routes and DNS resolver configuration
+func collectNetworkConfig(ctx context.Context, allInterfaces []Interface) ([]DHCPLease, []Route, Resolver, []string) {
+	var warnings []string
+	var conf Resolver
+	dns, err := getDNSEntries()
 	if err != nil {
-		fmt.Println(err)
+		warnings = append(warnings, err.Error())
 	}
-
-	ipMapGateway := make(map[string]string)
-
-	// always show DHCP server info
-	brief = false
-	ipMapGateway, err = getGatewaysAndDHCP(brief)
-	if err != nil {
-		fmt.Println(err)
+	for _, ns := range dns {
+		if len(ns) > 0 {
+			conf.Nameservers = append(conf.Nameservers, ns)
+		}
 	}
+	conf.Servers = dnsServers(conf.Nameservers, SourceSystem, "")
 
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetAutoWrapText(false)
-	table.SetHeader([]string{"Gateway", "DNS 1", "DNS 2"})
-	for ip := range ipMapGateway {
-		if arrayContains(ip, allIPv4) {
-			table.Append([]string{ipMapGateway[ip], dns[0], dns[1]})
-		}
-		if arrayContains(ip, allIPv6) {
-			table.Append([]string{ipMapGateway[ip], dns[0], dns[1]})
-		}
-		dns[0] = ""
-		dns[1] = ""
+	allRoutes, allDhcpInfo, err := getGatewaysAndDHCP(allInterfaces)
+	if err != nil {
+		warnings = append(warnings, err.Error())
 	}
-	table.Render()
+	return allDhcpInfo, allRoutes, conf, warnings
 }
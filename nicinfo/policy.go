@@ -0,0 +1,171 @@
+/*
+policy.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*/
+
+package nicinfo
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"net/netip"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Policy states what a host's network configuration is expected to look like; every field is optional
+type Policy struct {
+	Interfaces     map[string]InterfacePolicy `yaml:"interfaces"`
+	DefaultGateway string                     `yaml:"default_gateway"`
+	MinDNSServers  int                        `yaml:"min_dns_servers"`
+	DNSServers     []string                   `yaml:"dns_servers"`
+	NoSelfAssigned bool                       `yaml:"no_self_assigned"`
+}
+
+// InterfacePolicy states what one interface is expected to look like. Each entry of Addresses is either
+// a network, such as 10.1.2.0/24, that one of the addresses must be in, or an address, such as
+// 10.1.2.15 or 10.1.2.15/24, that the interface must have.
+type InterfacePolicy struct {
+	State     string   `yaml:"state"`
+	Addresses []string `yaml:"addresses"`
+	MTU       int      `yaml:"mtu"`
+	MAC       string   `yaml:"mac"`
+}
+
+// CheckResult is the outcome of one expectation of a policy
+type CheckResult struct {
+	Check  string `json:"check"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail"`
+}
+
+// ReadPolicy loads a policy from a YAML file; unknown keys are rejected so that typos do not
+// silently disable a check
+func ReadPolicy(fileName string) (*Policy, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var policy Policy
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&policy); err != nil {
+		return nil, fmt.Errorf("%s: %w", fileName, err)
+	}
+	for name, expected := range policy.Interfaces {
+		for _, addr := range expected.Addresses {
+			if _, err := parsePolicyAddress(addr); err != nil {
+				return nil, fmt.Errorf("%s: interface %s: %w", fileName, name, err)
+			}
+		}
+	}
+	return &policy, nil
+}
+
+// parsePolicyAddress accepts an address with or without a prefix length
+func parsePolicyAddress(addr string) (netip.Prefix, error) {
+	if strings.Contains(addr, "/") {
+		return netip.ParsePrefix(addr)
+	}
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(ip, -1), nil
+}
+
+// hasPolicyAddress reports whether one of addresses satisfies the policy entry addr
+func hasPolicyAddress(addresses []Address, addr string) bool {
+	expected, err := parsePolicyAddress(addr)
+	if err != nil {
+		return false
+	}
+	isNetwork := expected.Bits() >= 0 && expected.Masked().Addr() == expected.Addr()
+	for _, a := range addresses {
+		ip, err := netip.ParseAddr(a.IP)
+		if err != nil {
+			continue
+		}
+		ip = ip.WithZone("")
+		switch {
+		case isNetwork && expected.Contains(ip):
+			return true
+		case !isNetwork && ip == expected.Addr() && (expected.Bits() < 0 || expected.Bits() == a.PrefixLen):
+			return true
+		}
+	}
+	return false
+}
+
+// Check evaluates every expectation of the policy against snap, interfaces in name order first
+func (p *Policy) Check(snap *Snapshot) []CheckResult {
+	var results []CheckResult
+	for _, name := range slices.Sorted(maps.Keys(p.Interfaces)) {
+		expected := p.Interfaces[name]
+		idx := slices.IndexFunc(snap.Interfaces, func(nic Interface) bool { return nic.Name == name })
+		if idx < 0 {
+			results = append(results, CheckResult{name + " exists", false, "interface not found"})
+			continue
+		}
+		nic := snap.Interfaces[idx]
+		if len(expected.State) > 0 {
+			results = append(results, CheckResult{name + " is " + expected.State, nic.State() == expected.State, "state is " + nic.State()})
+		}
+		all := append(slices.Clone(nic.IPv4), nic.IPv6...)
+		for _, addr := range expected.Addresses {
+			results = append(results, CheckResult{name + " has address " + addr, hasPolicyAddress(all, addr), "addresses: " + strings.Join(addressStrings(all), " ")})
+		}
+		if expected.MTU > 0 {
+			results = append(results, CheckResult{name + " mtu " + strconv.Itoa(expected.MTU), nic.MTU == expected.MTU, "mtu is " + strconv.Itoa(nic.MTU)})
+		}
+		if len(expected.MAC) > 0 {
+			results = append(results, CheckResult{name + " mac " + expected.MAC, strings.EqualFold(nic.MAC, expected.MAC), "mac is " + nic.MAC})
+		}
+	}
+
+	if len(p.DefaultGateway) > 0 {
+		var gateways []string
+		for _, r := range snap.DefaultRoutes() {
+			gateways = append(gateways, r.Gateway)
+		}
+		results = append(results, CheckResult{"default gateway is " + p.DefaultGateway, slices.Contains(gateways, p.DefaultGateway), "default gateways: " + strings.Join(gateways, " ")})
+	}
+
+	servers := strings.Fields(dnsServerList(snap))
+	if p.MinDNSServers > 0 {
+		results = append(results, CheckResult{fmt.Sprintf("at least %d DNS servers", p.MinDNSServers), len(servers) >= p.MinDNSServers, fmt.Sprintf("%d DNS servers", len(servers))})
+	}
+	for _, server := range p.DNSServers {
+		results = append(results, CheckResult{"DNS server " + server, slices.Contains(servers, server), "DNS servers: " + strings.Join(servers, " ")})
+	}
+
+	if p.NoSelfAssigned {
+		var found []string
+		for _, nic := range snap.Interfaces {
+			for _, addr := range nic.IPv4 {
+				if isSelfAssigned(addr.IP) {
+					found = append(found, nic.Name+" "+addr.String())
+				}
+			}
+		}
+		detail := "none found"
+		if len(found) > 0 {
+			detail = strings.Join(found, ", ")
+		}
+		results = append(results, CheckResult{"no 169.254 addresses", len(found) == 0, detail})
+	}
+	return results
+}
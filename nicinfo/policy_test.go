@@ -0,0 +1,98 @@
+/*
+policy_test.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*/
+
+package nicinfo
+
+import "testing"
+
+func TestHasPolicyAddress(t *testing.T) {
+	addresses := []Address{{"10.1.2.15", 24}, {"fe80::1%eth0", 64}, {"2001:db8::15", 64}}
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		// a network, one of the addresses must be in it
+		{"10.1.2.0/24", true},
+		{"10.0.0.0/8", true},
+		{"10.9.0.0/16", false},
+		{"2001:db8::/32", true},
+		// a bare address, the prefix length does not matter
+		{"10.1.2.15", true},
+		{"10.1.2.16", false},
+		{"fe80::1", true},
+		// an address with a prefix length, both must match
+		{"10.1.2.15/24", true},
+		{"10.1.2.15/16", false},
+		{"2001:db8::15/64", true},
+		{"not an address", false},
+	}
+
+	for _, tt := range tests {
+		if got := hasPolicyAddress(addresses, tt.addr); got != tt.want {
+			t.Errorf("hasPolicyAddress(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestPolicyCheck(t *testing.T) {
+	snap := &Snapshot{
+		Interfaces: []Interface{
+			{Name: "eth0", MAC: "d4:b4:e7:aa:73:c2", MTU: 1500, Flags: []string{"up", "running"},
+				IPv4: []Address{{"10.1.2.15", 24}}},
+			{Name: "eth1", Flags: []string{"up"}, IPv4: []Address{{"169.254.7.1", 16}}},
+		},
+		Routes: []Route{{Destination: "0.0.0.0/0", Gateway: "10.1.2.1", Interface: "eth0", Flags: []string{"up", "gateway"}}},
+		Resolver: Resolver{
+			Servers: dnsServers([]string{"10.1.0.53", "10.1.0.53", "10.1.0.54"}, SourceResolvConf, ""),
+		},
+	}
+	policy := &Policy{
+		Interfaces: map[string]InterfacePolicy{
+			"eth0": {State: StateUp, Addresses: []string{"10.1.2.0/24", "10.1.2.15/16"}, MTU: 9000, MAC: "D4:B4:E7:AA:73:C2"},
+			"eth1": {State: StateUp},
+			"eth2": {State: StateUp},
+		},
+		DefaultGateway: "10.1.2.1",
+		MinDNSServers:  3,
+		DNSServers:     []string{"10.1.0.54"},
+		NoSelfAssigned: true,
+	}
+
+	want := []CheckResult{
+		{"eth0 is up", true, "state is up"},
+		{"eth0 has address 10.1.2.0/24", true, "addresses: 10.1.2.15/24"},
+		{"eth0 has address 10.1.2.15/16", false, "addresses: 10.1.2.15/24"},
+		{"eth0 mtu 9000", false, "mtu is 1500"},
+		{"eth0 mac D4:B4:E7:AA:73:C2", true, "mac is d4:b4:e7:aa:73:c2"},
+		{"eth1 is up", false, "state is down"},
+		{"eth2 exists", false, "interface not found"},
+		{"default gateway is 10.1.2.1", true, "default gateways: 10.1.2.1"},
+		// the same server listed twice counts once
+		{"at least 3 DNS servers", false, "2 DNS servers"},
+		{"DNS server 10.1.0.54", true, "DNS servers: 10.1.0.53 10.1.0.54"},
+		{"no 169.254 addresses", false, "eth1 169.254.7.1/16"},
+	}
+	results := policy.Check(snap)
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d: %+v", len(results), len(want), results)
+	}
+	for i, result := range results {
+		if result != want[i] {
+			t.Errorf("result %d:\n got %+v\nwant %+v", i, result, want[i])
+		}
+	}
+
+	if results := (&Policy{}).Check(snap); len(results) != 0 {
+		t.Errorf("an empty policy has no checks, got %+v", results)
+	}
+}
@@ -0,0 +1,165 @@
+/*
+resolvconf.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+*/
+
+package nicinfo
+
+import (
+	"bufio"
+	"io"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// adapted from: https://raw.githubusercontent.com/fiskeben/resolv/master/main.go
+// the keywords and limits follow resolv.conf(5) and glibc's resolv/res_init.c
+
+const resolvConfFile = "/etc/resolv.conf"
+
+// ResolverOptions are the "options" of resolv.conf; the defaults are those of glibc
+type ResolverOptions struct {
+	Ndots    int  `json:"ndots" yaml:"ndots"`
+	Timeout  int  `json:"timeout" yaml:"timeout"`
+	Attempts int  `json:"attempts" yaml:"attempts"`
+	Rotate   bool `json:"rotate" yaml:"rotate"`
+	EDNS0    bool `json:"edns0" yaml:"edns0"`
+	TrustAD  bool `json:"trust_ad" yaml:"trust_ad"`
+	// Other holds the remaining options as written, such as single-request or use-vc
+	Other []string `json:"other" yaml:"other"`
+}
+
+func defaultResolverOptions() *ResolverOptions {
+	return &ResolverOptions{Ndots: 1, Timeout: 5, Attempts: 2, Other: []string{}}
+}
+
+// String returns the options in resolv.conf notation, such as: ndots:1 timeout:5 attempts:2 rotate
+func (o *ResolverOptions) String() string {
+	if o == nil {
+		return ""
+	}
+	words := []string{
+		"ndots:" + strconv.Itoa(o.Ndots),
+		"timeout:" + strconv.Itoa(o.Timeout),
+		"attempts:" + strconv.Itoa(o.Attempts),
+	}
+	if o.Rotate {
+		words = append(words, "rotate")
+	}
+	if o.EDNS0 {
+		words = append(words, "edns0")
+	}
+	if o.TrustAD {
+		words = append(words, "trust-ad")
+	}
+	return strings.Join(append(words, o.Other...), " ")
+}
+
+// readResolvConf reads fileName, normally /etc/resolv.conf, and returns it as a Resolver
+func readResolvConf(fileName string) (Resolver, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return Resolver{}, err
+	}
+	defer f.Close()
+	return parseResolvConf(f)
+}
+
+// parseResolvConf returns the configuration of a resolv.conf formatted file. As with glibc,
+// keywords and values may be separated by spaces or tabs, lines starting with # or ; are
+// comments, invalid nameservers are skipped and the last of "domain" or "search" wins.
+func parseResolvConf(f io.Reader) (Resolver, error) {
+	conf := Resolver{
+		Nameservers: []string{},
+		Search:      []string{},
+		Sortlist:    []string{},
+		Options:     defaultResolverOptions(),
+	}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		kind := fields[0]
+		rest := fields[1:]
+
+		switch kind {
+		case "domain":
+			conf.Domain = rest[0]
+			conf.Search = []string{rest[0]}
+		case "nameserver":
+			// IPv6 link-local servers may carry a zone, as in fe80::1%eth0
+			if addr, err := netip.ParseAddr(rest[0]); err == nil {
+				conf.Nameservers = append(conf.Nameservers, addr.String())
+			}
+		case "search":
+			conf.Domain = ""
+			conf.Search = withoutComment(rest)
+		case "sortlist":
+			conf.Sortlist = append(conf.Sortlist, withoutComment(rest)...)
+		case "options":
+			for _, option := range withoutComment(rest) {
+				parseResolverOption(conf.Options, option)
+			}
+		}
+	}
+
+	conf.Servers = dnsServers(conf.Nameservers, SourceResolvConf, "")
+	return conf, scanner.Err()
+}
+
+// withoutComment drops a trailing comment from the values of a keyword
+func withoutComment(values []string) []string {
+	result := []string{}
+	for _, v := range values {
+		if strings.HasPrefix(v, "#") || strings.HasPrefix(v, ";") {
+			break
+		}
+		result = append(result, v)
+	}
+	return result
+}
+
+// parseResolverOption applies a single option, capping numeric values the way glibc does;
+// like glibc, a timeout or attempts of 0 is kept
+func parseResolverOption(opts *ResolverOptions, option string) {
+	name, value, _ := strings.Cut(option, ":")
+	n, err := strconv.Atoi(value)
+	numeric := err == nil && n >= 0
+
+	switch {
+	case name == "ndots" && numeric:
+		opts.Ndots = min(n, 15)
+	case name == "timeout" && numeric:
+		opts.Timeout = min(n, 30)
+	case name == "attempts" && numeric:
+		opts.Attempts = min(n, 5)
+	case name == "rotate":
+		opts.Rotate = true
+	case name == "edns0":
+		opts.EDNS0 = true
+	case name == "trust-ad":
+		opts.TrustAD = true
+	default:
+		opts.Other = append(opts.Other, option)
+	}
+}
@@ -0,0 +1,135 @@
+/*
+resolvconf_test.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*/
+
+package nicinfo
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseResolvConf(t *testing.T) {
+	defaults := defaultResolverOptions()
+	tests := []struct {
+		file        string
+		nameservers []string
+		domain      string
+		search      []string
+		sortlist    []string
+		options     *ResolverOptions
+	}{
+		{
+			file:        "ubuntu-resolved-stub.conf",
+			nameservers: []string{"127.0.0.53"},
+			search:      []string{"lan"},
+			options:     &ResolverOptions{Ndots: 1, Timeout: 5, Attempts: 2, EDNS0: true, TrustAD: true, Other: []string{}},
+		},
+		{
+			file:        "tabs.conf",
+			nameservers: []string{"10.0.0.1", "10.0.0.2"},
+			search:      []string{"corp.example", "lab.example"},
+			options:     &ResolverOptions{Ndots: 2, Timeout: 3, Attempts: 2, Other: []string{}},
+		},
+		{
+			file:        "comments.conf",
+			nameservers: []string{"192.168.1.1"},
+			search:      []string{"home.arpa", "example.com"},
+			sortlist:    []string{"10.0.0.0/255.0.0.0"},
+			options:     &ResolverOptions{Ndots: 1, Timeout: 5, Attempts: 2, Rotate: true, Other: []string{}},
+		},
+		{
+			file:        "ipv6-zone.conf",
+			nameservers: []string{"fe80::1%eth0", "2001:4860:4860::8888", "::ffff:192.0.2.53"},
+			options:     defaults,
+		},
+		{
+			file:        "domain-last-wins.conf",
+			nameservers: []string{},
+			domain:      "corp.example",
+			search:      []string{"corp.example"},
+			options:     defaults,
+		},
+		{
+			file:        "search-last-wins.conf",
+			nameservers: []string{},
+			search:      []string{"a.example", "b.example"},
+			options:     defaults,
+		},
+		{
+			file:        "sortlist.conf",
+			nameservers: []string{"10.0.0.53"},
+			sortlist:    []string{"130.155.160.0/255.255.240.0", "130.155.0.0", "10.1.0.0/16"},
+			options:     defaults,
+		},
+		{
+			file:        "options-clamped.conf",
+			nameservers: []string{"10.0.0.53"},
+			options:     &ResolverOptions{Ndots: 15, Timeout: 0, Attempts: 5, Other: []string{"single-request", "use-vc", "inet6", "no-such-option:7", "ndots:x"}},
+		},
+		{
+			file:        "empty.conf",
+			nameservers: []string{},
+			options:     defaults,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			conf, err := readResolvConf(filepath.Join("testdata", "resolvconf", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.search == nil {
+				tt.search = []string{}
+			}
+			if tt.sortlist == nil {
+				tt.sortlist = []string{}
+			}
+			if !reflect.DeepEqual(conf.Nameservers, tt.nameservers) {
+				t.Errorf("Nameservers = %q, want %q", conf.Nameservers, tt.nameservers)
+			}
+			if conf.Domain != tt.domain {
+				t.Errorf("Domain = %q, want %q", conf.Domain, tt.domain)
+			}
+			if !reflect.DeepEqual(conf.Search, tt.search) {
+				t.Errorf("Search = %q, want %q", conf.Search, tt.search)
+			}
+			if !reflect.DeepEqual(conf.Sortlist, tt.sortlist) {
+				t.Errorf("Sortlist = %q, want %q", conf.Sortlist, tt.sortlist)
+			}
+			if !reflect.DeepEqual(conf.Options, tt.options) {
+				t.Errorf("Options = %+v, want %+v", conf.Options, tt.options)
+			}
+			if len(conf.Servers) != len(conf.Nameservers) {
+				t.Fatalf("got %d Servers for %d nameservers", len(conf.Servers), len(conf.Nameservers))
+			}
+			for i, server := range conf.Servers {
+				if server.Address != conf.Nameservers[i] || server.Source != SourceResolvConf {
+					t.Errorf("Servers[%d] = %+v", i, server)
+				}
+			}
+		})
+	}
+}
+
+func TestParseResolverOptionString(t *testing.T) {
+	opts := defaultResolverOptions()
+	for _, option := range []string{"ndots:20", "timeout:0", "attempts:9", "rotate", "edns0", "trust-ad", "use-vc"} {
+		parseResolverOption(opts, option)
+	}
+	want := "ndots:15 timeout:0 attempts:5 rotate edns0 trust-ad use-vc"
+	if got := opts.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
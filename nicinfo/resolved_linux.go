@@ -0,0 +1,185 @@
+//go:build linux
+// +build linux
+
+/*
+resolved_linux.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+*/
+
+package nicinfo
+
+import (
+	"bufio"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// files written by systemd-resolved and systemd-networkd
+const (
+	resolvedUpstreamConf = "/run/systemd/resolve/resolv.conf"
+	resolvedLinkDir      = "/run/systemd/resolve/netif"
+	networkdLinkDir      = "/run/systemd/netif/links"
+)
+
+// resolvedStubAddresses are the listeners of systemd-resolved's local DNS stub
+var resolvedStubAddresses = []string{"127.0.0.53", "127.0.0.54"}
+
+// resolvedStub returns the stub address when /etc/resolv.conf points at systemd-resolved,
+// either because it is a symlink to stub-resolv.conf or because it only lists the stub
+func resolvedStub(conf Resolver) string {
+	if target, err := filepath.EvalSymlinks(resolvConfFile); err == nil && strings.HasSuffix(target, "stub-resolv.conf") {
+		return resolvedStubAddresses[0]
+	}
+	if len(conf.Nameservers) > 0 && slices.Contains(resolvedStubAddresses, conf.Nameservers[0]) {
+		return conf.Nameservers[0]
+	}
+	return ""
+}
+
+// applyResolved replaces the stub nameserver of conf with the upstream servers that
+// systemd-resolved actually queries, attributing them to a link when possible. The search
+// list and options of the stub configuration are kept since those are what applications use.
+func applyResolved(conf *Resolver) error {
+	stub := resolvedStub(*conf)
+	if len(stub) == 0 {
+		return nil
+	}
+	conf.StubResolver = stub
+
+	upstream, err := readResolvConf(resolvedUpstreamConf)
+	if err != nil {
+		return err
+	}
+
+	conf.Servers = []DNSServer{}
+	conf.LinkDomains = make(map[string][]string)
+
+	var linkServers []string
+	for _, link := range resolvedLinks() {
+		for _, ns := range link.servers {
+			conf.Servers = append(conf.Servers, dnsServers([]string{ns}, SourceResolved, link.name)...)
+			linkServers = append(linkServers, ns)
+		}
+		if len(link.domains) > 0 {
+			conf.LinkDomains[link.name] = link.domains
+		}
+	}
+	for _, ns := range upstream.Nameservers {
+		if !slices.Contains(linkServers, ns) {
+			conf.Servers = append(conf.Servers, dnsServers([]string{ns}, SourceResolved, "")...)
+		}
+	}
+
+	conf.Nameservers = []string{}
+	for _, server := range conf.Servers {
+		if !slices.Contains(conf.Nameservers, server.Address) {
+			conf.Nameservers = append(conf.Nameservers, server.Address)
+		}
+	}
+	return nil
+}
+
+// resolvedLink is the per-interface DNS configuration known to systemd-resolved
+type resolvedLink struct {
+	name    string
+	servers []string
+	domains []string
+}
+
+// linkStateFiles lists the directories holding per-link state files and the keys each one
+// uses: systemd-resolved writes SERVERS=, which includes servers pushed by NetworkManager
+// over D-Bus, while systemd-networkd writes DNS= and lists routing-only domains, without
+// their ~ prefix, under ROUTE_DOMAINS=
+var linkStateFiles = []struct {
+	dir             string
+	serverKey       string
+	routeDomainsKey string
+}{
+	{resolvedLinkDir, "SERVERS", ""},
+	{networkdLinkDir, "DNS", "ROUTE_DOMAINS"},
+}
+
+// resolvedLinks reads the per-link state files, named after the interface index, of both
+// systemd-resolved and systemd-networkd; servers and domains found in both are merged
+func resolvedLinks() []resolvedLink {
+	var allLinks []resolvedLink
+	adapters, err := net.Interfaces()
+	if err != nil {
+		return nil
+	}
+	for _, iface := range adapters {
+		link := resolvedLink{name: iface.Name}
+		for _, state := range linkStateFiles {
+			values := readStateFile(filepath.Join(state.dir, strconv.Itoa(iface.Index)))
+			for _, server := range strings.Fields(values[state.serverKey]) {
+				if ns := parseResolvedServer(server); len(ns) > 0 && !slices.Contains(link.servers, ns) {
+					link.servers = append(link.servers, ns)
+				}
+			}
+			domains := strings.Fields(values["DOMAINS"])
+			if len(state.routeDomainsKey) > 0 {
+				for _, domain := range strings.Fields(values[state.routeDomainsKey]) {
+					domains = append(domains, "~"+strings.TrimPrefix(domain, "~"))
+				}
+			}
+			for _, domain := range domains {
+				if !slices.Contains(link.domains, domain) {
+					link.domains = append(link.domains, domain)
+				}
+			}
+		}
+		if len(link.servers) > 0 || len(link.domains) > 0 {
+			allLinks = append(allLinks, link)
+		}
+	}
+	return allLinks
+}
+
+// readStateFile returns the KEY=value pairs of a systemd state file; a missing file is empty
+func readStateFile(fileName string) map[string]string {
+	values := make(map[string]string)
+	f, err := os.Open(fileName)
+	if err != nil {
+		return values
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		if key, value, found := strings.Cut(line, "="); found {
+			values[key] = value
+		}
+	}
+	return values
+}
+
+// parseResolvedServer extracts the address from the forms systemd uses for a DNS server:
+// 1.1.1.1, 1.1.1.1:53, [2001:db8::1]:53, fe80::1%3 and any of these followed by #servername
+func parseResolvedServer(server string) string {
+	server, _, _ = strings.Cut(server, "#")
+	if addrPort, err := netip.ParseAddrPort(server); err == nil {
+		return addrPort.Addr().String()
+	}
+	if addr, err := netip.ParseAddr(server); err == nil {
+		return addr.String()
+	}
+	return ""
+}
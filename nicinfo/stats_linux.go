@@ -0,0 +1,77 @@
+//go:build linux
+// +build linux
+
+/*
+stats_linux.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+*/
+
+package nicinfo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const sysClassNet = "/sys/class/net"
+
+// ReadInterfaceStats returns the traffic counters of the named interface from
+// /sys/class/net/<name>/statistics
+func ReadInterfaceStats(name string) (*InterfaceStats, error) {
+	dir := filepath.Join(sysClassNet, name, "statistics")
+	if _, err := os.Stat(dir); err != nil {
+		return nil, err
+	}
+
+	counter := func(fileName string) uint64 {
+		content, err := os.ReadFile(filepath.Join(dir, fileName))
+		if err != nil {
+			return 0
+		}
+		value, _ := strconv.ParseUint(strings.TrimSpace(string(content)), 10, 64)
+		return value
+	}
+
+	return &InterfaceStats{
+		RxBytes:    counter("rx_bytes"),
+		RxPackets:  counter("rx_packets"),
+		RxErrors:   counter("rx_errors"),
+		RxDropped:  counter("rx_dropped"),
+		RxOverruns: counter("rx_fifo_errors"),
+		Multicast:  counter("multicast"),
+		TxBytes:    counter("tx_bytes"),
+		TxPackets:  counter("tx_packets"),
+		TxErrors:   counter("tx_errors"),
+		TxDropped:  counter("tx_dropped"),
+		TxOverruns: counter("tx_fifo_errors"),
+	}, nil
+}
+
+// ReadLinkSpeed returns the link speed of the named interface in Mbit/s from /sys/class/net/<name>/speed;
+// interfaces without a carrier, and virtual ones, have no speed
+func ReadLinkSpeed(name string) (int, error) {
+	content, err := os.ReadFile(filepath.Join(sysClassNet, name, "speed"))
+	if err != nil {
+		return 0, err
+	}
+	speed, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		return 0, err
+	}
+	if speed <= 0 {
+		return 0, errors.New("link speed of " + name + " is unknown")
+	}
+	return speed, nil
+}
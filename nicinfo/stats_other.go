@@ -0,0 +1,35 @@
+//go:build !linux
+// +build !linux
+
+/*
+stats_other.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+*/
+
+package nicinfo
+
+import (
+	"errors"
+	"runtime"
+)
+
+// ReadInterfaceStats returns the traffic counters of the named interface;
+// this is only implemented on Linux
+func ReadInterfaceStats(name string) (*InterfaceStats, error) {
+	return nil, errors.New("interface statistics are not supported on " + runtime.GOOS)
+}
+
+// ReadLinkSpeed returns the link speed of the named interface in Mbit/s;
+// this is only implemented on Linux
+func ReadLinkSpeed(name string) (int, error) {
+	return 0, errors.New("link speed is not supported on " + runtime.GOOS)
+}
@@ -18,210 +18,203 @@ THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLI
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"net"
+	"maps"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 
+	"github.com/jftuga/nics/nicinfo"
 	"github.com/olekukonko/tablewriter"
 )
 
 const version = "1.6.2"
 
-func isBriefEntry(ifaceName, macAddr, mtu, flags string, ipv4List, ipv6List []string, debug bool) bool {
-	if debug {
-		fmt.Println("isBriefEntry:", ifaceName)
-	}
-	if strings.Contains(flags, "loopback") {
-		if debug {
-			fmt.Println("   not_brief: loopback flag")
-		}
-		return false
-	}
-	if strings.HasPrefix(macAddr, "00:00:00:00:00:00") {
-		if debug {
-			fmt.Println("   not_brief: NULL macAddr")
-		}
-		return false
-	}
-	if 0 == len(ipv4List) {
-		if debug {
-			fmt.Println("   not_brief: no IP addresses")
-		}
-		return false
-	}
-	for _, ipv4 := range ipv4List {
-		if strings.HasPrefix(ipv4, "169.254.") {
-			if debug {
-				fmt.Println("   not_brief: self assigned:", ipv4)
-			}
-			return false
-		}
-	}
-	if debug {
-		fmt.Println("    is_brief: true")
+// column names of the tables, also used as the headers of csv and tsv output
+var (
+	briefInterfaceHeaders = []string{"Name", "IP", "Gateway", "Mac Address", "MTU", "Flags"}
+	interfaceHeaders      = []string{"Name", "IPv4", "IPv6", "Gateway", "Mac Address", "MTU", "Flags"}
+	dhcpHeaders           = []string{"Name", "DHCP Server", "Lease Start", "Lease Expiration", "Lease Duration"}
+	gatewayHeaders        = []string{"Gateway", "Interface", "Metric"}
+	dnsHeaders            = []string{"DNS Server", "Family", "Source", "Interface", "Domains"}
+)
+
+// addressStrings returns each address in CIDR notation
+func addressStrings(allAddresses []nicinfo.Address) []string {
+	var result []string
+	for _, addr := range allAddresses {
+		result = append(result, addr.String())
 	}
-	return true
+	return result
 }
 
-func extractIPAddrs(ifaceName string, allAddresses []net.Addr, brief bool) ([]string, []string) {
-	var allIPv4 []string
-	var allIPv6 []string
-
-	for _, netAddr := range allAddresses {
-		address := netAddr.String()
-		if strings.Contains(address, ":") {
-			allIPv6 = append(allIPv6, address)
-		} else {
-			allIPv4 = append(allIPv4, address)
+// interfaceGateways maps each interface name to the gateways of its default routes
+func interfaceGateways(defaultRoutes []nicinfo.Route) map[string][]string {
+	gateways := make(map[string][]string)
+	for _, r := range defaultRoutes {
+		if !slices.Contains(gateways[r.Interface], r.Gateway) {
+			gateways[r.Interface] = append(gateways[r.Interface], r.Gateway)
 		}
 	}
-	return allIPv4, allIPv6
+	return gateways
 }
 
-func networkInterfaces(brief bool, debug bool, singleInterface string) ([]string, []string, []string) {
-	adapters, err := net.Interfaces()
-	if err != nil {
-		fmt.Print(fmt.Errorf("%+v\n", err.Error()))
-		return nil, nil, nil
-	}
-
-	foundSingleInterface := false
+func networkInterfaces(allInterfaces []nicinfo.Interface, defaultRoutes []nicinfo.Route, brief bool, singleInterface string, tracker *changeTracker) {
 	if len(singleInterface) > 0 {
 		brief = false
-		singleInterface = strings.ToLower(singleInterface)
 	}
 
 	table := tablewriter.NewWriter(os.Stdout)
 	table.SetAutoWrapText(false)
+	headers := interfaceHeaders
 	if brief {
-		table.SetHeader([]string{"Name", "IP", "Mac Address", "MTU", "Flags"})
-	} else {
-		table.SetHeader([]string{"Name", "IPv4", "IPv6", "Mac Address", "MTU", "Flags"})
+		headers = briefInterfaceHeaders
 	}
+	table.SetHeader(headers)
 
-	var v4Addresses []string
-	var v6Addresses []string
-	var allRenderedInterfaces []string
-	for _, iface := range adapters {
-		//fmt.Printf("%T %v\n", iface, iface)
-		allAddresses, err := iface.Addrs()
-		if err != nil {
-			fmt.Print(fmt.Errorf("%+v\n", err.Error()))
-			return nil, nil, nil
-		}
+	gateways := interfaceGateways(defaultRoutes)
 
-		allIPv4, allIPv6 := extractIPAddrs(iface.Name, allAddresses, brief)
-		if debug {
-			fmt.Println()
-			fmt.Println("---------------------")
-			fmt.Println(iface.Name, allAddresses)
-			fmt.Println("ipv4:", allIPv4)
-			fmt.Println("ipv6:", allIPv6)
-		}
-
-		ifaceName := strings.ToLower(iface.Name)
-		if len(singleInterface) > 0 && ifaceName != singleInterface {
-			continue
-		} else if len(singleInterface) > 0 && ifaceName == singleInterface {
-			foundSingleInterface = true
-		}
-		macAddr := iface.HardwareAddr.String()
-		mtu := strconv.Itoa(iface.MTU)
-		flags := iface.Flags.String()
+	for _, nic := range allInterfaces {
+		allIPv4 := addressStrings(nic.IPv4)
+		allIPv6 := addressStrings(nic.IPv6)
+		mtu := strconv.Itoa(nic.MTU)
+		flags := strings.Join(nic.Flags, "|")
+		gateway := strings.Join(gateways[nic.Name], "\n")
 
-		if brief && isBriefEntry(ifaceName, macAddr, mtu, flags, allIPv4, allIPv6, debug) {
+		if brief {
 			joined := strings.Join(allIPv4, "\n") // + "\n" + strings.Join(allIPv6, "\n")
-			table.Append([]string{iface.Name, joined, macAddr, mtu, flags})
-			allRenderedInterfaces = append(allRenderedInterfaces, iface.Name)
-			for _, ipWithMask := range allIPv4 {
-				ip := strings.Split(ipWithMask, "/")
-				v4Addresses = append(v4Addresses, ip[0])
-			}
+			tracker.appendRow(table, "interfaces", nic.Name, headers, []string{nic.Name, joined, gateway, nic.MAC, mtu, flags})
 			continue
 		}
 
-		if !brief {
-			table.SetAutoWrapText(true)
-			table.SetRowLine(true)
-			table.Append([]string{ifaceName, strings.Join(allIPv4, "\n"), strings.Join(allIPv6, "\n"), macAddr, mtu, strings.Replace(flags, "|", "\n", -1)})
-			allRenderedInterfaces = append(allRenderedInterfaces, iface.Name)
-			for _, ipWithMask := range allIPv4 {
-				ip := strings.Split(ipWithMask, "/")
-				v4Addresses = append(v4Addresses, ip[0])
-			}
-			for _, ipWithMask := range allIPv6 {
-				ip := strings.Split(ipWithMask, "/")
-				v6Addresses = append(v6Addresses, ip[0])
-			}
-		}
+		table.SetAutoWrapText(true)
+		table.SetRowLine(true)
+		tracker.appendRow(table, "interfaces", nic.Name, headers, []string{strings.ToLower(nic.Name), strings.Join(allIPv4, "\n"), strings.Join(allIPv6, "\n"), gateway, nic.MAC, mtu, strings.Replace(flags, "|", "\n", -1)})
 	}
-	if len(singleInterface) > 0 && !foundSingleInterface {
-		_, _ = fmt.Fprintf(os.Stderr, "\ninterface not found: %v\n", singleInterface)
-	} else {
-		table.Render()
+	table.Render()
+}
+
+// renderDHCPTable shows one row per DHCP lease
+func renderDHCPTable(allDhcpInfo []nicinfo.DHCPLease) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader(dhcpHeaders)
+	for _, lease := range allDhcpInfo {
+		table.Append(dhcpRow(lease))
 	}
+	table.Render()
+}
 
-	return v4Addresses, v6Addresses, allRenderedInterfaces
+func dhcpRow(lease nicinfo.DHCPLease) []string {
+	return []string{lease.Interface, lease.Server, lease.LeaseStart, lease.LeaseExpires, lease.LeaseDuration}
 }
 
-// FormatWithCorrectPlurals ensures time units use singular form when the value is 1.
-// For example:
-// - "1 days" becomes "1 day"
-// - "2 mins" remains "2 mins"
-// - "0 hrs, 1 mins" becomes "0 hrs, 1 min"
-//
-// The function handles days, hrs, mins, and secs units.
-func FormatWithCorrectPlurals(duration string) string {
-	// Replace "1 days" with "1 day"
-	duration = strings.Replace(duration, "1 days", "1 day", 1)
+func gatewayRow(r nicinfo.Route) []string {
+	return []string{r.Gateway, r.Interface, strconv.FormatInt(r.Metric, 10)}
+}
 
-	// Replace "1 hrs" with "1 hr"
-	duration = strings.Replace(duration, "1 hrs", "1 hr", 1)
+// dnsRows returns one row per DNS server, the domains of a link are shown with its first server;
+// links with domains but without servers get a row of their own
+func dnsRows(conf nicinfo.Resolver) [][]string {
+	var rows [][]string
+	shownDomains := make(map[string]bool)
+	for _, server := range conf.Servers {
+		domains := ""
+		if len(server.Interface) > 0 && !shownDomains[server.Interface] {
+			domains = strings.Join(conf.LinkDomains[server.Interface], " ")
+			shownDomains[server.Interface] = true
+		}
+		rows = append(rows, []string{server.Address, server.Family, server.Source, server.Interface, domains})
+	}
+	for _, iface := range slices.Sorted(maps.Keys(conf.LinkDomains)) {
+		if !shownDomains[iface] {
+			rows = append(rows, []string{"", "", nicinfo.SourceResolved, iface, strings.Join(conf.LinkDomains[iface], " ")})
+		}
+	}
+	return rows
+}
 
-	// Replace "1 mins" with "1 min"
-	duration = strings.Replace(duration, "1 mins", "1 min", 1)
+// gatewayAndDNS shows one row per default route, preferred route first, followed by every DNS server
+// and the search domains
+func gatewayAndDNS(defaultRoutes []nicinfo.Route, conf nicinfo.Resolver, tracker *changeTracker) {
+	if len(defaultRoutes) > 0 {
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetAutoWrapText(false)
+		table.SetHeader(gatewayHeaders)
+		for _, r := range defaultRoutes {
+			rowKey := r.Interface
+			if tracker != nil && slices.Contains(tracker.rows["gateways"], rowKey) {
+				rowKey += " " + r.Gateway
+			}
+			tracker.appendRow(table, "gateways", rowKey, gatewayHeaders, gatewayRow(r))
+		}
+		table.Render()
+	}
 
-	// Replace "1 secs" with "1 sec"
-	duration = strings.Replace(duration, "1 secs", "1 sec", 1)
+	if len(conf.Servers) == 0 && len(conf.Search) == 0 && len(conf.LinkDomains) == 0 {
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader(dnsHeaders)
+	for _, row := range dnsRows(conf) {
+		tracker.appendRow(table, "dns", strings.TrimSpace(row[0]+" "+row[3]), dnsHeaders, row)
+	}
 
-	return duration
+	var caption []string
+	if len(conf.Search) > 0 {
+		caption = append(caption, "Search domains: "+strings.Join(conf.Search, " "))
+	}
+	if len(conf.StubResolver) > 0 {
+		caption = append(caption, "Stub resolver: "+conf.StubResolver)
+	}
+	if len(caption) > 0 {
+		table.SetCaption(true, strings.Join(caption, "; "))
+	}
+	table.Render()
 }
 
-// ShortenLeaseDuration trims trailing zero units from a lease duration string.
-//
-// For example:
-// - "1 days, 0 hrs, 0 mins, 0 secs" becomes "1 day"
-// - "1 days, 0 hrs, 5 mins, 0 secs" becomes "1 day, 0 hrs, 5 mins"
-// - "1 days, 0 hrs, 0 mins, 1 secs" becomes "1 day, 0 hrs, 0 mins, 1 sec"
-//
-// The function preserves any non-zero units and removes only trailing zero units.
-// It also ensures correct singular/plural forms.
-func ShortenLeaseDuration(leaseDuration string) string {
-	// Split the duration into its components
-	components := strings.Split(leaseDuration, ", ")
-
-	// Find the last non-zero component
-	lastNonZeroIndex := len(components) - 1
-	for i := len(components) - 1; i > 0; i-- {
-		if !strings.HasPrefix(components[i], "0 ") {
-			break
-		}
-		lastNonZeroIndex = i - 1
+// resolverTable shows the resolv.conf settings beyond the DNS servers
+func resolverTable(conf nicinfo.Resolver) {
+	if conf.Options == nil {
+		return
 	}
 
-	// If all components after the first one are zero, return just the first component
-	if lastNonZeroIndex == 0 {
-		return FormatWithCorrectPlurals(components[0])
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Resolver", "Value"})
+	if len(conf.Domain) > 0 {
+		table.Append([]string{"Domain", conf.Domain})
 	}
+	if len(conf.Sortlist) > 0 {
+		table.Append([]string{"Sortlist", strings.Join(conf.Sortlist, " ")})
+	}
+	table.Append([]string{"Options", conf.Options.String()})
+	table.Render()
+}
 
-	// Join the components up to and including the last non-zero one
-	shortened := strings.Join(components[:lastNonZeroIndex+1], ", ")
-
-	// Apply pluralization formatting
-	return FormatWithCorrectPlurals(shortened)
+// renderTables shows every table of the default view; tracker is only used by watch mode
+func renderTables(snap *nicinfo.Snapshot, brief, found bool, singleInterface string, showStats bool, tracker *changeTracker) {
+	for _, warning := range snap.Warnings {
+		fmt.Println(warning)
+	}
+	if found {
+		networkInterfaces(snap.Interfaces, snap.DefaultRoutes(), brief, singleInterface, tracker)
+		if showStats {
+			statsTable(snap.Interfaces)
+		}
+	}
+	if len(snap.DHCP) > 0 {
+		renderDHCPTable(snap.DHCP)
+	}
+	gatewayAndDNS(snap.DefaultRoutes(), snap.Resolver, tracker)
+	if !brief {
+		resolverTable(snap.Resolver)
+	}
 }
 
 func main() {
@@ -229,6 +222,14 @@ func main() {
 	argsDebug := flag.Bool("d", false, "show debug information")
 	argsVersion := flag.Bool("v", false, "show program version")
 	argsSingleInterface := flag.String("i", "", "interface name")
+	argsOutput := flag.String("o", "table", "output format: table, json, yaml, ndjson, csv, tsv, markdown or html")
+	argsRows := flag.String("rows", "interface", "csv and tsv rows: one per interface or one per address (interface or address)")
+	argsSection := flag.String("section", "all", "csv and tsv section: all, interfaces, dhcp, gateways or dns")
+	argsStats := flag.Bool("s", false, "show traffic and error counters (Linux only)")
+	argsExport := flag.String("export", "", "print environment variable assignments for a shell: sh, fish or powershell")
+	argsTemplate := flag.String("template", "", "execute a Go template over the collected information")
+	argsTemplateFile := flag.String("template-file", "", "execute the Go template in this file")
+	argsWatch := flag.String("w", "", "refresh the tables every `interval` seconds, highlighting what changed")
 
 	flag.Usage = func() {
 		pgmName := os.Args[0]
@@ -236,8 +237,20 @@ func main() {
 			pgmName = os.Args[0][2:]
 		}
 		fmt.Fprintf(os.Stderr, "\n%s: Display information about Network Interface Cards (NICs)\n", pgmName)
-		fmt.Fprintf(os.Stderr, "usage: %s [options]\n", pgmName)
+		fmt.Fprintf(os.Stderr, "usage: %s [options] [command]\n", pgmName)
 		flag.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\ncommands:\n")
+		fmt.Fprintf(os.Stderr, "  routes [-i interface]\n    \tshow the IPv4 and IPv6 routing tables\n")
+		fmt.Fprintf(os.Stderr, "  via <ip>\n    \tshow the interface, source address and gateway used to reach ip\n")
+		fmt.Fprintf(os.Stderr, "  stats [-n interval] [-c count] [-i interface]\n    \tshow live throughput until interrupted, then a summary (Linux only)\n")
+		fmt.Fprintf(os.Stderr, "  events [-o text|ndjson] [-i interface]\n    \tshow link, address and route changes as they happen (Linux only)\n")
+		fmt.Fprintf(os.Stderr, "  hooks [-n interval] [-on-ip-change cmd] [-on-link-down cmd] [-on-gateway-change cmd]\n    \trun commands when an address, link state or gateway changes\n")
+		fmt.Fprintf(os.Stderr, "  snapshot save <file.json>\n    \tsave every interface, route, lease and DNS setting for a later diff\n")
+		fmt.Fprintf(os.Stderr, "  diff <before.json> [after.json]\n    \tshow what changed between two snapshots, or since a snapshot\n")
+		fmt.Fprintf(os.Stderr, "  check -policy <policy.yaml>\n    \tverify the expectations of a policy, exit code 1 if any fail\n")
+		fmt.Fprintf(os.Stderr, "  nagios [-i interfaces] [-address ip] [-gateway] [-warn-errors n] [-crit-errors n] [-warn-drops n] [-crit-drops n]\n    \tmonitoring plugin, prints OK, WARNING, CRITICAL or UNKNOWN and exits with 0, 1, 2 or 3\n")
+		fmt.Fprintf(os.Stderr, "  get [-cidr] <field> [interface]\n    \tprint one value for scripts, field is one of: %s\n", strings.Join(getFields, ", "))
+		fmt.Fprintf(os.Stderr, "  serve [-listen address|unix:path] [-token token]\n    \tserve Prometheus metrics and a JSON API, default address %s\n", defaultListen)
 	}
 	flag.Parse()
 
@@ -247,6 +260,114 @@ func main() {
 		os.Exit(0)
 	}
 
-	allIPv4, allIPv6, allRenderedInterfaces := networkInterfaces(!(*argsAllDetails), *argsDebug, *argsSingleInterface)
-	gatewayAndDNS(allIPv4, allIPv6, allRenderedInterfaces, !(*argsAllDetails))
+	if flag.NArg() > 0 {
+		switch flag.Arg(0) {
+		case "routes":
+			os.Exit(routesCommand(flag.Args()[1:], *argsSingleInterface))
+		case "via":
+			os.Exit(viaCommand(flag.Args()[1:]))
+		case "stats":
+			os.Exit(statsCommand(flag.Args()[1:], !(*argsAllDetails), *argsSingleInterface))
+		case "events":
+			os.Exit(eventsCommand(flag.Args()[1:], *argsOutput, *argsSingleInterface))
+		case "hooks":
+			os.Exit(hooksCommand(flag.Args()[1:], *argsSingleInterface))
+		case "snapshot":
+			os.Exit(snapshotCommand(flag.Args()[1:]))
+		case "diff":
+			os.Exit(diffCommand(flag.Args()[1:], *argsOutput))
+		case "check":
+			os.Exit(checkCommand(flag.Args()[1:], *argsOutput))
+		case "nagios":
+			os.Exit(nagiosCommand(flag.Args()[1:], *argsSingleInterface))
+		case "serve":
+			os.Exit(serveCommand(flag.Args()[1:]))
+		case "get":
+			os.Exit(getCommand(flag.Args()[1:], *argsSingleInterface))
+		default:
+			fmt.Fprintf(os.Stderr, "unknown command: %s\n", flag.Arg(0))
+			flag.Usage()
+			os.Exit(1)
+		}
+	}
+
+	if !isValidOutputFormat(*argsOutput) {
+		fmt.Fprintf(os.Stderr, "invalid output format: %s\n", *argsOutput)
+		os.Exit(1)
+	}
+	if len(*argsExport) > 0 && !slices.Contains(exportShells, *argsExport) {
+		fmt.Fprintf(os.Stderr, "invalid shell: %s\n", *argsExport)
+		os.Exit(1)
+	}
+	if !slices.Contains(delimitedRows, *argsRows) {
+		fmt.Fprintf(os.Stderr, "invalid rows: %s\n", *argsRows)
+		os.Exit(1)
+	}
+	if !slices.Contains(delimitedSections, *argsSection) {
+		fmt.Fprintf(os.Stderr, "invalid section: %s\n", *argsSection)
+		os.Exit(1)
+	}
+
+	brief := !(*argsAllDetails)
+	opts := nicinfo.Options{Brief: brief, Interface: *argsSingleInterface}
+	if *argsDebug {
+		opts.Debug = os.Stderr
+	}
+	if len(*argsWatch) > 0 {
+		if *argsOutput != "table" {
+			fmt.Fprintf(os.Stderr, "-w can only be used with table output\n")
+			os.Exit(1)
+		}
+		interval, err := parseInterval(*argsWatch)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(watch(opts, interval, *argsStats))
+	}
+
+	snap, err := nicinfo.Collect(context.Background(), opts)
+	found := true
+	if errors.Is(err, nicinfo.ErrInterfaceNotFound) {
+		_, _ = fmt.Fprintf(os.Stderr, "\n%v\n", err)
+		found = false
+	} else if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	err = nil
+	switch {
+	case len(*argsExport) > 0:
+		err = renderExport(os.Stdout, *argsExport, snap)
+	case len(*argsTemplate) > 0 || len(*argsTemplateFile) > 0:
+		var text string
+		if text, err = templateText(*argsTemplate, *argsTemplateFile); err == nil {
+			err = renderTemplate(os.Stdout, text, snap)
+		}
+	case *argsOutput == "csv" || *argsOutput == "tsv":
+		for _, warning := range snap.Warnings {
+			fmt.Fprintln(os.Stderr, warning)
+		}
+		err = renderDelimited(os.Stdout, *argsOutput, snap, brief && len(*argsSingleInterface) == 0, *argsRows == "address", *argsSection)
+	case *argsOutput == "markdown":
+		err = renderMarkdown(os.Stdout, snap, brief && len(*argsSingleInterface) == 0)
+	case *argsOutput == "html":
+		err = renderHTML(os.Stdout, snap, brief && len(*argsSingleInterface) == 0)
+	case *argsOutput != "table":
+		for _, warning := range snap.Warnings {
+			fmt.Fprintln(os.Stderr, warning)
+		}
+		err = renderStructured(os.Stdout, *argsOutput, snap)
+	default:
+		renderTables(snap, brief, found, *argsSingleInterface, *argsStats, nil)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	// scripts can tell that -i named an interface that does not exist
+	if !found {
+		os.Exit(1)
+	}
 }
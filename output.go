@@ -0,0 +1,135 @@
+/*
+output.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+*/
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/jftuga/nics/nicinfo"
+	"gopkg.in/yaml.v3"
+)
+
+var outputFormats = []string{"table", "json", "yaml", "ndjson", "csv", "tsv", "markdown", "html"}
+
+func isValidOutputFormat(format string) bool {
+	return slices.Contains(outputFormats, format)
+}
+
+// ndjsonRecord wraps each item of a snapshot so that every line of ndjson output can be
+// consumed on its own; Type is one of: interface, dhcp, route, resolver, warning, event, change, check
+type ndjsonRecord struct {
+	SchemaVersion int    `json:"schema_version"`
+	Type          string `json:"type"`
+	Data          any    `json:"data"`
+}
+
+// renderStructured writes snap to w in one of the non-table output formats
+func renderStructured(w io.Writer, format string, snap *nicinfo.Snapshot) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(snap)
+	case "yaml":
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(snap); err != nil {
+			return err
+		}
+		return enc.Close()
+	case "ndjson":
+		var records []ndjsonRecord
+		for _, nic := range snap.Interfaces {
+			records = append(records, ndjsonRecord{snap.SchemaVersion, "interface", nic})
+		}
+		for _, lease := range snap.DHCP {
+			records = append(records, ndjsonRecord{snap.SchemaVersion, "dhcp", lease})
+		}
+		for _, r := range snap.Routes {
+			records = append(records, ndjsonRecord{snap.SchemaVersion, "route", r})
+		}
+		records = append(records, ndjsonRecord{snap.SchemaVersion, "resolver", snap.Resolver})
+		for _, warning := range snap.Warnings {
+			records = append(records, ndjsonRecord{snap.SchemaVersion, "warning", warning})
+		}
+
+		enc := json.NewEncoder(w)
+		for _, rec := range records {
+			if err := enc.Encode(rec); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown output format: %s", format)
+}
+
+// renderRecords writes a list, such as the result of a diff or check, in one of the non-table output
+// formats; in ndjson every item becomes a record of recordType
+func renderRecords[T any](w io.Writer, format, recordType string, items []T) error {
+	if items == nil {
+		items = []T{}
+	}
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(items)
+	case "yaml":
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(items); err != nil {
+			return err
+		}
+		return enc.Close()
+	case "ndjson":
+		enc := json.NewEncoder(w)
+		for _, item := range items {
+			if err := enc.Encode(ndjsonRecord{nicinfo.SchemaVersion, recordType, item}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown output format: %s", format)
+}
+
+// renderRows writes a single table, such as the result of a diff or check, as csv, tsv, markdown
+// or html; the title and note are only shown in markdown and html
+func renderRows(w io.Writer, format, title string, headers []string, rows [][]string, note string) error {
+	switch format {
+	case "csv", "tsv":
+		out := csv.NewWriter(w)
+		if format == "tsv" {
+			out.Comma = '\t'
+		}
+		if err := out.Write(headers); err != nil {
+			return err
+		}
+		return out.WriteAll(rows)
+	case "markdown", "html":
+		r := newReportHeader(nil)
+		r.Sections = []reportSection{{Title: title, Headers: headers, Rows: rows, Note: note}}
+		if format == "html" {
+			return htmlReport.Execute(w, r)
+		}
+		return writeMarkdown(w, r)
+	}
+	return fmt.Errorf("unknown output format: %s", format)
+}
@@ -0,0 +1,173 @@
+/*
+report.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*/
+
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jftuga/nics/nicinfo"
+)
+
+// reportSection is one table of a markdown or html report; cells may contain several lines
+type reportSection struct {
+	Title   string
+	Headers []string
+	Rows    [][]string
+	Note    string
+}
+
+// report is everything shown by the markdown and html output
+type report struct {
+	Hostname  string
+	Collected string
+	Version   string
+	Warnings  []string
+	Sections  []reportSection
+}
+
+// newReportHeader fills in the host, time and version shown at the top of every report
+func newReportHeader(warnings []string) report {
+	hostname, _ := os.Hostname()
+	return report{
+		Hostname:  hostname,
+		Collected: time.Now().Format("2006-01-02 15:04:05 -07:00"),
+		Version:   version,
+		Warnings:  warnings,
+	}
+}
+
+// newReport builds the interface, DHCP, route and DNS tables of snap; empty tables are left out
+func newReport(snap *nicinfo.Snapshot, brief bool) report {
+	r := newReportHeader(snap.Warnings)
+
+	headers, rows := interfaceRows(snap.Interfaces, snap.DefaultRoutes(), brief, false, "\n")
+	r.Sections = append(r.Sections, reportSection{Title: "Interfaces", Headers: headers, Rows: rows})
+
+	rows = nil
+	for _, lease := range snap.DHCP {
+		rows = append(rows, dhcpRow(lease))
+	}
+	r.Sections = append(r.Sections, reportSection{Title: "DHCP Leases", Headers: dhcpHeaders, Rows: rows})
+
+	rows = nil
+	for _, route := range snap.Routes {
+		rows = append(rows, routeRow(route))
+	}
+	r.Sections = append(r.Sections, reportSection{Title: "Routes", Headers: routeHeaders, Rows: rows})
+
+	var notes []string
+	if len(snap.Resolver.Search) > 0 {
+		notes = append(notes, "Search domains: "+strings.Join(snap.Resolver.Search, " "))
+	}
+	if len(snap.Resolver.StubResolver) > 0 {
+		notes = append(notes, "Stub resolver: "+snap.Resolver.StubResolver)
+	}
+	r.Sections = append(r.Sections, reportSection{Title: "DNS", Headers: dnsHeaders, Rows: dnsRows(snap.Resolver), Note: strings.Join(notes, "; ")})
+
+	var sections []reportSection
+	for _, section := range r.Sections {
+		if len(section.Rows) > 0 {
+			sections = append(sections, section)
+		}
+	}
+	r.Sections = sections
+	return r
+}
+
+// markdownCell escapes a cell for a GitHub pipe table, which can't contain newlines
+var markdownCell = strings.NewReplacer("|", "\\|", "\n", "<br>")
+
+// renderMarkdown writes snap as GitHub flavored markdown, one pipe table per section
+func renderMarkdown(w io.Writer, snap *nicinfo.Snapshot, brief bool) error {
+	return writeMarkdown(w, newReport(snap, brief))
+}
+
+// writeMarkdown writes r as GitHub flavored markdown
+func writeMarkdown(w io.Writer, r report) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\nCollected %s by nics %s\n", r.Hostname, r.Collected, r.Version)
+	if len(r.Warnings) > 0 {
+		b.WriteString("\n## Warnings\n\n")
+		for _, warning := range r.Warnings {
+			fmt.Fprintf(&b, "* %s\n", warning)
+		}
+	}
+	for _, section := range r.Sections {
+		fmt.Fprintf(&b, "\n## %s\n\n", section.Title)
+		b.WriteString("| " + strings.Join(section.Headers, " | ") + " |\n")
+		b.WriteString(strings.Repeat("| --- ", len(section.Headers)) + "|\n")
+		for _, row := range section.Rows {
+			cells := make([]string, len(row))
+			for i, cell := range row {
+				cells[i] = markdownCell.Replace(cell)
+			}
+			b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		}
+		if len(section.Note) > 0 {
+			fmt.Fprintf(&b, "\n%s\n", section.Note)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// htmlReport is a self-contained page, without external style sheets or scripts
+var htmlReport = template.Must(template.New("report").Funcs(template.FuncMap{
+	"lines": func(cell string) []string { return strings.Split(cell, "\n") },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>nics: {{.Hostname}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 0.5em; }
+th, td { border: 1px solid #bbb; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+th { background: #eee; }
+td { font-family: monospace; }
+.meta, .note { color: #555; }
+.warning { color: #a40; }
+</style>
+</head>
+<body>
+<h1>{{.Hostname}}</h1>
+<p class="meta">Collected {{.Collected}} by nics {{.Version}}</p>
+{{- range .Warnings}}
+<p class="warning">{{.}}</p>
+{{- end}}
+{{- range .Sections}}
+<h2>{{.Title}}</h2>
+<table>
+<tr>{{range .Headers}}<th>{{.}}</th>{{end}}</tr>
+{{- range .Rows}}
+<tr>{{range .}}<td>{{range $i, $line := lines .}}{{if $i}}<br>{{end}}{{$line}}{{end}}</td>{{end}}</tr>
+{{- end}}
+</table>
+{{- if .Note}}
+<p class="note">{{.Note}}</p>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
+
+// renderHTML writes snap as a self-contained html page
+func renderHTML(w io.Writer, snap *nicinfo.Snapshot, brief bool) error {
+	return htmlReport.Execute(w, newReport(snap, brief))
+}
@@ -0,0 +1,108 @@
+/*
+routes.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jftuga/nics/nicinfo"
+	"github.com/olekukonko/tablewriter"
+)
+
+// routesCommand implements: nics routes [-i interface]
+// the full routing table is only available on Linux; other platforms list their default routes
+func routesCommand(args []string, singleInterface string) int {
+	fs := flag.NewFlagSet("routes", flag.ExitOnError)
+	argsSingleInterface := fs.String("i", singleInterface, "only show routes using this interface")
+	_ = fs.Parse(args)
+
+	snap, err := nicinfo.Collect(context.Background(), nicinfo.Options{Interface: *argsSingleInterface})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	var v4Routes, v6Routes []nicinfo.Route
+	for _, r := range snap.Routes {
+		if len(*argsSingleInterface) > 0 && !strings.EqualFold(r.Interface, *argsSingleInterface) {
+			continue
+		}
+		if r.IsIPv6() {
+			v6Routes = append(v6Routes, r)
+		} else {
+			v4Routes = append(v4Routes, r)
+		}
+	}
+
+	if len(v4Routes) > 0 {
+		renderRouteTable(v4Routes)
+	}
+	if len(v6Routes) > 0 {
+		renderRouteTable(v6Routes)
+	}
+	return 0
+}
+
+// viaCommand implements: nics via <ip>
+// it reports which interface, source address and gateway traffic to ip would use
+func viaCommand(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: nics via <ip>")
+		return 1
+	}
+
+	snap, err := nicinfo.Collect(context.Background(), nicinfo.Options{})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	path, err := snap.Via(args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	gateway := path.Gateway
+	if len(gateway) == 0 {
+		gateway = "directly connected"
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Destination", "Interface", "Source", "Gateway", "Route", "Metric"})
+	table.Append([]string{path.Destination, path.Interface, path.Source, gateway, path.Route.Destination, strconv.FormatInt(path.Route.Metric, 10)})
+	table.Render()
+	return 0
+}
+
+var routeHeaders = []string{"Destination", "Gateway", "Interface", "Metric", "Flags"}
+
+func routeRow(r nicinfo.Route) []string {
+	return []string{r.Destination, r.Gateway, r.Interface, strconv.FormatInt(r.Metric, 10), strings.Join(r.Flags, "|")}
+}
+
+func renderRouteTable(allRoutes []nicinfo.Route) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader(routeHeaders)
+	for _, r := range allRoutes {
+		table.Append(routeRow(r))
+	}
+	table.Render()
+}
@@ -0,0 +1,177 @@
+/*
+serve.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jftuga/nics/nicinfo"
+)
+
+// defaultListen is the address nics serve listens on without -listen; only local clients can connect,
+// since without -token anyone who can reach it gets the full interface, DNS and DHCP inventory
+const defaultListen = "127.0.0.1:9192"
+
+// handleMetrics collects a new snapshot of every interface for each scrape
+func handleMetrics(w http.ResponseWriter, r *http.Request) {
+	snap, err := nicinfo.Collect(r.Context(), nicinfo.Options{})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var buf bytes.Buffer
+	writeMetrics(&buf, snap)
+	w.Header().Set("Content-Type", metricsContentType)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// writeJSON writes v the same way as: nics -o json
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// handleSnapshot returns a handler that collects a new snapshot of every interface and responds
+// with the part of it that pick returns; pick returns nil for resources that do not exist
+func handleSnapshot(pick func(snap *nicinfo.Snapshot, r *http.Request) any) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		snap, err := nicinfo.Collect(r.Context(), nicinfo.Options{})
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		v := pick(snap, r)
+		if v == nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found: " + r.URL.Path})
+			return
+		}
+		writeJSON(w, http.StatusOK, v)
+	}
+}
+
+// requireToken rejects requests without an "Authorization: Bearer <token>" header
+func requireToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// listen opens a TCP address, or a Unix socket when address starts with unix:
+func listen(address string) (net.Listener, error) {
+	path, ok := strings.CutPrefix(address, "unix:")
+	if !ok {
+		return net.Listen("tcp", address)
+	}
+	// a socket left behind by a process that did not shut down cleanly would make Listen fail;
+	// it is only removed when nothing accepts connections on it anymore
+	if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		conn, err := net.DialTimeout("unix", path, time.Second)
+		if err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s: already in use by another process", path)
+		}
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			_ = os.Remove(path)
+		}
+	}
+	// without -token, anyone who can connect to the socket can query it, so it is created
+	// accessible to its owner only instead of being restricted after it already accepts connections
+	return listenPrivateUnix(path)
+}
+
+// serveCommand implements: nics serve [-listen address] [-token token]
+// it serves /metrics and the JSON API until interrupted
+func serveCommand(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	argsListen := fs.String("listen", defaultListen, "address to listen on, or unix:/path/to/socket")
+	argsToken := fs.String("token", os.Getenv("NICS_TOKEN"), "require this bearer token, defaults to $NICS_TOKEN")
+	_ = fs.Parse(args)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", handleMetrics)
+	mux.HandleFunc("GET /interfaces", handleSnapshot(func(snap *nicinfo.Snapshot, r *http.Request) any {
+		return snap.Interfaces
+	}))
+	mux.HandleFunc("GET /interfaces/{name}", handleSnapshot(func(snap *nicinfo.Snapshot, r *http.Request) any {
+		idx := slices.IndexFunc(snap.Interfaces, func(nic nicinfo.Interface) bool { return nic.Name == r.PathValue("name") })
+		if idx < 0 {
+			return nil
+		}
+		return snap.Interfaces[idx]
+	}))
+	mux.HandleFunc("GET /routes", handleSnapshot(func(snap *nicinfo.Snapshot, r *http.Request) any {
+		return snap.Routes
+	}))
+	mux.HandleFunc("GET /dns", handleSnapshot(func(snap *nicinfo.Snapshot, r *http.Request) any {
+		return snap.Resolver
+	}))
+	mux.HandleFunc("GET /dhcp", handleSnapshot(func(snap *nicinfo.Snapshot, r *http.Request) any {
+		return snap.DHCP
+	}))
+
+	var handler http.Handler = mux
+	if len(*argsToken) > 0 {
+		handler = requireToken(*argsToken, mux)
+	}
+	srv := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+
+	listener, err := listen(*argsListen)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdown)
+	}()
+
+	if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -1,8 +1,8 @@
-//go:build freebsd
-// +build freebsd
+//go:build windows
+// +build windows
 
 /*
-nics_darwin.go
+serve_windows.go
 -John Taylor
 2019-08-03
 
@@ -12,15 +12,14 @@ MIT License; Copyright (c) 2019 John Taylor
 Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
 The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
 THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
-
 */
 
 package main
 
-func getGatewaysAndDHCP(brief bool) (map[string]string, error) {
-	return nil, nil
-}
+import "net"
 
-func gatewayAndDNS(allIPv4, allIPv6, allRenderedInterfaces []string, brief bool) {
-	return
+// listenPrivateUnix creates a Unix socket; Windows has no umask, access to the socket
+// follows the ACL of the directory it is created in
+func listenPrivateUnix(path string) (net.Listener, error) {
+	return net.Listen("unix", path)
 }
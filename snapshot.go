@@ -0,0 +1,134 @@
+/*
+snapshot.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jftuga/nics/nicinfo"
+	"github.com/olekukonko/tablewriter"
+)
+
+// snapshotCommand implements: nics snapshot save <file.json>
+// every interface is saved, regardless of -a and -i, so that nothing is missed by a later diff
+func snapshotCommand(args []string) int {
+	fs := flag.NewFlagSet("snapshot", flag.ExitOnError)
+	_ = fs.Parse(args)
+	if fs.NArg() != 2 || fs.Arg(0) != "save" {
+		fmt.Fprintf(os.Stderr, "usage: nics snapshot save <file.json>\n")
+		return 1
+	}
+
+	snap, err := nicinfo.Collect(context.Background(), nicinfo.Options{})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fileName := fs.Arg(1)
+	out := os.Stdout
+	if fileName != "-" {
+		if out, err = os.Create(fileName); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer out.Close()
+	}
+	if err := renderStructured(out, "json", snap); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// diffCommand implements: nics diff <before.json> [after.json]
+// without a second file, the saved snapshot is compared with the live system; like diff(1), the exit
+// code is 0 when nothing changed, 1 when something did and 2 on errors
+func diffCommand(args []string, output string) int {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	_ = fs.Parse(args)
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		fmt.Fprintf(os.Stderr, "usage: nics diff <before.json> [after.json]\n")
+		return 2
+	}
+
+	before, err := nicinfo.ReadSnapshot(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	var after *nicinfo.Snapshot
+	if fs.NArg() == 2 {
+		after, err = nicinfo.ReadSnapshot(fs.Arg(1))
+		if err == nil && !nicinfo.SameSelection(before, after) {
+			err = fmt.Errorf("%s and %s were saved with different -a or -i options", fs.Arg(0), fs.Arg(1))
+		}
+	} else {
+		// select the same interfaces as the saved snapshot; one that no longer exists is reported as absent
+		after, err = nicinfo.Collect(context.Background(), before.Selection())
+		if errors.Is(err, nicinfo.ErrInterfaceNotFound) {
+			err = nil
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	changes := nicinfo.Compare(before, after)
+	if err := renderChanges(output, changes); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if len(changes) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// renderChanges shows one row per change, or writes them in one of the other output formats
+func renderChanges(output string, changes []nicinfo.Change) error {
+	headers := []string{"Interface", "Field", "Before", "After"}
+	switch output {
+	case "table":
+		if len(changes) == 0 {
+			fmt.Println("no differences")
+			return nil
+		}
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetAutoWrapText(false)
+		table.SetRowLine(true)
+		table.SetHeader(headers)
+		for _, change := range changes {
+			table.Append([]string{change.Interface, change.Field, strings.ReplaceAll(change.Old, " ", "\n"), strings.ReplaceAll(change.New, " ", "\n")})
+		}
+		table.Render()
+		return nil
+	case "csv", "tsv", "markdown", "html":
+		var rows [][]string
+		for _, change := range changes {
+			rows = append(rows, []string{change.Interface, change.Field, change.Old, change.New})
+		}
+		note := ""
+		if len(changes) == 0 {
+			note = "no differences"
+		}
+		return renderRows(os.Stdout, output, "Differences", headers, rows, note)
+	}
+	return renderRecords(os.Stdout, output, "change", changes)
+}
@@ -0,0 +1,279 @@
+/*
+stats.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/jftuga/nics/nicinfo"
+	"github.com/olekukonko/tablewriter"
+)
+
+// humanBytes formats a byte count with binary units, such as: 512 B, 1.5 KiB, 3.2 GiB
+func humanBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// humanCount formats a packet or error count with decimal units, such as: 999, 1.5K, 3.2M
+func humanCount(n uint64) string {
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%d", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%c", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// statsTable shows the traffic counters of each interface that has them
+func statsTable(allInterfaces []nicinfo.Interface) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Name", "RX Bytes", "RX Packets", "RX Errors", "RX Dropped", "RX Overruns", "Multicast",
+		"TX Bytes", "TX Packets", "TX Errors", "TX Dropped", "TX Overruns"})
+	alignment := []int{tablewriter.ALIGN_LEFT}
+	for i := 1; i < 12; i++ {
+		alignment = append(alignment, tablewriter.ALIGN_RIGHT)
+	}
+	table.SetColumnAlignment(alignment)
+	rows := 0
+	for _, nic := range allInterfaces {
+		s := nic.Stats
+		if s == nil {
+			continue
+		}
+		table.Append([]string{nic.Name, humanBytes(s.RxBytes), humanCount(s.RxPackets), humanCount(s.RxErrors), humanCount(s.RxDropped),
+			humanCount(s.RxOverruns), humanCount(s.Multicast), humanBytes(s.TxBytes), humanCount(s.TxPackets), humanCount(s.TxErrors),
+			humanCount(s.TxDropped), humanCount(s.TxOverruns)})
+		rows++
+	}
+	if rows > 0 {
+		table.Render()
+	}
+}
+
+// humanRate formats bits per second with decimal units, such as: 800 bit/s, 1.5 Mbit/s
+func humanRate(bitsPerSecond float64) string {
+	const unit = 1000
+	if bitsPerSecond < unit {
+		return fmt.Sprintf("%.0f bit/s", bitsPerSecond)
+	}
+	div, exp := float64(unit), 0
+	for m := bitsPerSecond / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cbit/s", bitsPerSecond/div, "kMGTPE"[exp])
+}
+
+// parseInterval accepts either a number of seconds, such as 2 or 0.5, or a Go duration, such as 500ms
+func parseInterval(value string) (time.Duration, error) {
+	var interval time.Duration
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		interval = time.Duration(seconds * float64(time.Second))
+	} else if interval, err = time.ParseDuration(value); err != nil {
+		return 0, fmt.Errorf("invalid interval: %s", value)
+	}
+	if interval <= 0 {
+		return 0, fmt.Errorf("interval must be greater than zero: %s", value)
+	}
+	return interval, nil
+}
+
+// isTerminal reports whether stdout is a terminal, in which case output can be redrawn in place
+func isTerminal() bool {
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func clearScreen() {
+	fmt.Print("\033[H\033[2J")
+}
+
+// counterDelta returns the increase of a counter, treating a counter that went backwards,
+// because the interface was reset, as no increase
+func counterDelta(previous, current uint64) uint64 {
+	if current < previous {
+		return 0
+	}
+	return current - previous
+}
+
+// rateSummary tracks the min, average and max of a rate over all samples
+type rateSummary struct {
+	min, max, total float64
+	samples         int
+}
+
+func (r *rateSummary) add(value float64) {
+	if r.samples == 0 || value < r.min {
+		r.min = value
+	}
+	if value > r.max {
+		r.max = value
+	}
+	r.total += value
+	r.samples++
+}
+
+func (r *rateSummary) avg() float64 {
+	if r.samples == 0 {
+		return 0
+	}
+	return r.total / float64(r.samples)
+}
+
+// interfaceSummary is what statsCommand reports for an interface when it exits
+type interfaceSummary struct {
+	rxBits, txBits, rxPackets, txPackets rateSummary
+	errors, dropped                      uint64
+}
+
+// statsCommand implements: nics stats [-n interval] [-c count]
+// it samples the counters of the selected interfaces and shows their throughput until
+// interrupted, followed by a summary
+func statsCommand(args []string, brief bool, singleInterface string) int {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	argsInterval := fs.String("n", "1", "sampling interval, in seconds or as a duration such as 500ms")
+	argsCount := fs.Int("c", 0, "stop after this many samples; 0 runs until interrupted")
+	argsSingleInterface := fs.String("i", singleInterface, "interface name")
+	_ = fs.Parse(args)
+
+	interval, err := parseInterval(*argsInterval)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	snap, err := nicinfo.Collect(context.Background(), nicinfo.Options{Brief: brief, Interface: *argsSingleInterface})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	var names []string
+	previous := make(map[string]*nicinfo.InterfaceStats)
+	for _, nic := range snap.Interfaces {
+		if nic.Stats != nil {
+			names = append(names, nic.Name)
+			previous[nic.Name] = nic.Stats
+		}
+	}
+	if len(names) == 0 {
+		fmt.Fprintln(os.Stderr, "no interface statistics are available")
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	summaries := make(map[string]*interfaceSummary)
+	for _, name := range names {
+		summaries[name] = &interfaceSummary{}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	lastSample := time.Now()
+	for samples := 0; *argsCount == 0 || samples < *argsCount; samples++ {
+		select {
+		case <-ctx.Done():
+			statsSummaryTable(names, summaries)
+			return 0
+		case now := <-ticker.C:
+			elapsed := now.Sub(lastSample).Seconds()
+			lastSample = now
+
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetAutoWrapText(false)
+			table.SetHeader([]string{"Name", "RX Rate", "TX Rate", "RX Pkt/s", "TX Pkt/s", "RX Errors", "TX Errors", "RX Dropped", "TX Dropped"})
+			table.SetColumnAlignment([]int{tablewriter.ALIGN_LEFT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT,
+				tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT})
+			table.SetCaption(true, now.Format("2006-01-02 15:04:05")+", interval "+interval.String())
+			for _, name := range names {
+				current, err := nicinfo.ReadInterfaceStats(name)
+				if err != nil {
+					continue
+				}
+				last := previous[name]
+				previous[name] = current
+
+				rxBits := float64(counterDelta(last.RxBytes, current.RxBytes)*8) / elapsed
+				txBits := float64(counterDelta(last.TxBytes, current.TxBytes)*8) / elapsed
+				rxPackets := float64(counterDelta(last.RxPackets, current.RxPackets)) / elapsed
+				txPackets := float64(counterDelta(last.TxPackets, current.TxPackets)) / elapsed
+				rxErrors := counterDelta(last.RxErrors, current.RxErrors)
+				txErrors := counterDelta(last.TxErrors, current.TxErrors)
+				rxDropped := counterDelta(last.RxDropped, current.RxDropped)
+				txDropped := counterDelta(last.TxDropped, current.TxDropped)
+
+				summary := summaries[name]
+				summary.rxBits.add(rxBits)
+				summary.txBits.add(txBits)
+				summary.rxPackets.add(rxPackets)
+				summary.txPackets.add(txPackets)
+				summary.errors += rxErrors + txErrors
+				summary.dropped += rxDropped + txDropped
+
+				table.Append([]string{name, humanRate(rxBits), humanRate(txBits), fmt.Sprintf("%.0f", rxPackets), fmt.Sprintf("%.0f", txPackets),
+					humanCount(rxErrors), humanCount(txErrors), humanCount(rxDropped), humanCount(txDropped)})
+			}
+			if isTerminal() {
+				clearScreen()
+			}
+			table.Render()
+		}
+	}
+	statsSummaryTable(names, summaries)
+	return 0
+}
+
+// statsSummaryTable shows the min/avg/max throughput and total errors and drops of each interface
+func statsSummaryTable(names []string, summaries map[string]*interfaceSummary) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Name", "RX Min", "RX Avg", "RX Max", "TX Min", "TX Avg", "TX Max", "Pkt/s Avg", "Errors", "Dropped"})
+	table.SetColumnAlignment([]int{tablewriter.ALIGN_LEFT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT})
+	for _, name := range names {
+		s := summaries[name]
+		if s.rxBits.samples == 0 {
+			continue
+		}
+		table.Append([]string{name, humanRate(s.rxBits.min), humanRate(s.rxBits.avg()), humanRate(s.rxBits.max),
+			humanRate(s.txBits.min), humanRate(s.txBits.avg()), humanRate(s.txBits.max),
+			fmt.Sprintf("%.0f", s.rxPackets.avg()+s.txPackets.avg()), humanCount(s.errors), humanCount(s.dropped)})
+	}
+	fmt.Println()
+	table.Render()
+}
@@ -0,0 +1,119 @@
+/*
+template.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/jftuga/nics/nicinfo"
+)
+
+// listStrings formats each element of a slice or array, such as []string or []nicinfo.Address
+func listStrings(list any) ([]string, error) {
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("expected a list, got %T", list)
+	}
+	result := make([]string, v.Len())
+	for i := range result {
+		result[i] = fmt.Sprint(v.Index(i).Interface())
+	}
+	return result, nil
+}
+
+// parseCIDR accepts a nicinfo.Address, a string in CIDR notation or a plain address,
+// which is treated as a host address
+func parseCIDR(addr any) (netip.Prefix, error) {
+	if a, ok := addr.(nicinfo.Address); ok {
+		addr = a.String()
+	}
+	if prefix, err := netip.ParsePrefix(fmt.Sprint(addr)); err == nil {
+		return prefix, nil
+	}
+	ip, err := netip.ParseAddr(fmt.Sprint(addr))
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("not an address: %v", addr)
+	}
+	return netip.PrefixFrom(ip, ip.BitLen()), nil
+}
+
+// isEmptyAddress reports whether addr is what first returns for an empty list
+func isEmptyAddress(addr any) bool {
+	return addr == nil || fmt.Sprint(addr) == ""
+}
+
+// templateFuncs are the helpers available to -template and -template-file, in addition to the
+// functions built into text/template
+var templateFuncs = template.FuncMap{
+	// join formats the elements of a list and joins them with sep: {{join .IPv4 ","}}
+	"join": func(list any, sep string) (string, error) {
+		items, err := listStrings(list)
+		return strings.Join(items, sep), err
+	},
+	// first returns the first element of a list, or nothing when it is empty: {{first .IPv4}}
+	"first": func(list any) (any, error) {
+		v := reflect.ValueOf(list)
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return nil, fmt.Errorf("expected a list, got %T", list)
+		}
+		if v.Len() == 0 {
+			return "", nil
+		}
+		return v.Index(0).Interface(), nil
+	},
+	// cidrHost returns the address without its prefix length: {{cidrHost (first .IPv4)}};
+	// like first, it returns nothing for an interface without addresses
+	"cidrHost": func(addr any) (string, error) {
+		if isEmptyAddress(addr) {
+			return "", nil
+		}
+		prefix, err := parseCIDR(addr)
+		return prefix.Addr().String(), err
+	},
+	// prefixLen returns the prefix length of an address: {{prefixLen (first .IPv4)}}, or 0 without one
+	"prefixLen": func(addr any) (int, error) {
+		if isEmptyAddress(addr) {
+			return 0, nil
+		}
+		prefix, err := parseCIDR(addr)
+		return prefix.Bits(), err
+	},
+	"upper": func(s any) string {
+		return strings.ToUpper(fmt.Sprint(s))
+	},
+}
+
+// templateText returns text, or the content of fileName when it is given
+func templateText(text, fileName string) (string, error) {
+	if len(fileName) == 0 {
+		return text, nil
+	}
+	content, err := os.ReadFile(fileName)
+	return string(content), err
+}
+
+// renderTemplate executes a Go template over snap
+func renderTemplate(w io.Writer, text string, snap *nicinfo.Snapshot) error {
+	tmpl, err := template.New("nics").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, snap)
+}
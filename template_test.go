@@ -0,0 +1,78 @@
+/*
+template_test.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*/
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jftuga/nics/nicinfo"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	snap := &nicinfo.Snapshot{
+		Interfaces: []nicinfo.Interface{
+			{Name: "eth0", IPv4: []nicinfo.Address{{IP: "192.0.2.2", PrefixLen: 24}, {IP: "192.0.2.3", PrefixLen: 24}}},
+			// an interface without any IPv4 address, such as ifb0
+			{Name: "ifb0", IPv6: []nicinfo.Address{{IP: "fe80::1", PrefixLen: 64}}},
+		},
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "join",
+			text: `{{range .Interfaces}}{{.Name}}={{join .IPv4 ","}};{{end}}`,
+			want: "eth0=192.0.2.2/24,192.0.2.3/24;ifb0=;",
+		},
+		{
+			name: "cidrHost of first",
+			text: `{{range .Interfaces}}{{.Name}}={{cidrHost (first .IPv4)}};{{end}}`,
+			want: "eth0=192.0.2.2;ifb0=;",
+		},
+		{
+			name: "prefixLen of first",
+			text: `{{range .Interfaces}}{{.Name}}={{prefixLen (first .IPv4)}};{{end}}`,
+			want: "eth0=24;ifb0=0;",
+		},
+		{
+			name: "plain addresses",
+			text: `{{cidrHost "2001:db8::1/64"}} {{prefixLen "10.0.0.5"}} {{upper (cidrHost "fe80::a")}}`,
+			want: "2001:db8::1 32 FE80::A",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := renderTemplate(&b, tt.text, snap); err != nil {
+				t.Fatal(err)
+			}
+			if b.String() != tt.want {
+				t.Errorf("got %q, want %q", b.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderTemplateInvalidAddress(t *testing.T) {
+	var b strings.Builder
+	err := renderTemplate(&b, `{{cidrHost "eth0"}}`, &nicinfo.Snapshot{})
+	if err == nil || !strings.Contains(err.Error(), "not an address") {
+		t.Errorf("got %v, want a not an address error", err)
+	}
+}
@@ -0,0 +1,144 @@
+/*
+watch.go
+-John Taylor
+2019-08-03
+
+Display information about Network Interface Cards (NICs)
+
+MIT License; Copyright (c) 2019 John Taylor
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jftuga/nics/nicinfo"
+	"github.com/olekukonko/tablewriter"
+)
+
+// highlight is the SGR sequence used for cells that changed since the previous refresh
+const highlight = "\033[1;33m"
+
+// changeTracker remembers the cells shown by the previous refresh so that the next one can
+// highlight what changed; cells are keyed by table, row and column
+type changeTracker struct {
+	previous map[string]string
+	current  map[string]string
+	rows     map[string][]string
+}
+
+func newChangeTracker() *changeTracker {
+	return &changeTracker{current: make(map[string]string), rows: make(map[string][]string)}
+}
+
+// appendRow adds row to table, highlighting each cell whose value differs from the previous refresh;
+// a nil tracker appends the row unchanged
+func (c *changeTracker) appendRow(table *tablewriter.Table, tableName, rowKey string, headers, row []string) {
+	if c == nil {
+		table.Append(row)
+		return
+	}
+	c.rows[tableName] = append(c.rows[tableName], rowKey)
+	cells := make([]string, len(row))
+	for i, value := range row {
+		key := tableName + "/" + rowKey + "/" + headers[i]
+		c.current[key] = value
+		old, seen := c.previous[key]
+		if c.previous == nil || (seen && old == value) || (!seen && len(value) == 0) {
+			cells[i] = value
+			continue
+		}
+		var lines []string
+		for _, line := range strings.Split(value, "\n") {
+			lines = append(lines, highlight+line+"\033[0m")
+		}
+		cells[i] = strings.Join(lines, "\n")
+	}
+	table.Append(cells)
+}
+
+// removedRows returns the rows of tableName that were shown by the previous refresh but not by this one
+func (c *changeTracker) removedRows(tableName string) []string {
+	if c == nil || c.previous == nil {
+		return nil
+	}
+	var removed []string
+	prefix := tableName + "/"
+	for key := range c.previous {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		rowKey := strings.TrimPrefix(key, prefix)
+		rowKey = rowKey[:strings.LastIndex(rowKey, "/")]
+		if !slices.Contains(c.rows[tableName], rowKey) && !slices.Contains(removed, rowKey) {
+			removed = append(removed, rowKey)
+		}
+	}
+	slices.Sort(removed)
+	return removed
+}
+
+// next makes the cells of this refresh the baseline for the following one
+func (c *changeTracker) next() {
+	c.previous = c.current
+	c.current = make(map[string]string)
+	c.rows = make(map[string][]string)
+}
+
+// watch collects and shows the interface, gateway and DNS tables every interval until interrupted,
+// highlighting the cells that changed and listing the rows that disappeared since the previous refresh
+func watch(opts nicinfo.Options, interval time.Duration, showStats bool) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	tracker := newChangeTracker()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		snap, err := nicinfo.Collect(ctx, opts)
+		found := true
+		if errors.Is(err, nicinfo.ErrInterfaceNotFound) {
+			found = false
+		} else if err != nil && ctx.Err() == nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if ctx.Err() != nil {
+			return 0
+		}
+
+		if isTerminal() {
+			clearScreen()
+		}
+		fmt.Printf("%s, every %s\n", time.Now().Format("2006-01-02 15:04:05"), interval)
+		if !found {
+			fmt.Printf("interface not found: %s\n", opts.Interface)
+		}
+		renderTables(snap, opts.Brief, found, opts.Interface, showStats, tracker)
+		for _, tableName := range []string{"interfaces", "gateways", "dns"} {
+			if removed := tracker.removedRows(tableName); len(removed) > 0 {
+				fmt.Printf("%sremoved from %s: %s\033[0m\n", highlight, tableName, strings.Join(removed, ", "))
+			}
+		}
+		tracker.next()
+
+		select {
+		case <-ctx.Done():
+			return 0
+		case <-ticker.C:
+		}
+	}
+}